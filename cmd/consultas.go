@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"concurso-go-app/internal/api"
+	"concurso-go-app/internal/models"
+	"concurso-go-app/internal/services"
+)
+
+// Campos que podem ser pedidos em ?campos= (lote só nos processados)
+var (
+	camposConcurso   = []string{"id", "nome", "status", "data_prova"}
+	camposProcessado = []string{"id", "nome", "status", "data_prova", "lote"}
+)
+
+const (
+	limitePadrao = 100
+	limiteMaximo = 1000
+)
+
+// GET /concursos?data=&status=&nome=&ordem=&limite=&cursor=&campos=
+func listarConcursosHandler(w http.ResponseWriter, r *http.Request) {
+	filtro, campos, err := lerFiltroConsulta(r, camposConcurso)
+	if err != nil {
+		api.EscreverErro(w, r, "", err)
+		return
+	}
+	if filtro.Lote != "" {
+		api.EscreverErro(w, r, "", api.ErroValidacao("Filtro lote só existe em /concursos-processados", map[string]string{"campo": "lote"}))
+		return
+	}
+
+	service := services.NewConcursoService(slog.Default())
+
+	pagina, err := service.ListarConcursos(r.Context(), filtro, campos)
+	if err != nil {
+		api.EscreverErro(w, r, "Erro ao consultar concursos", err)
+		return
+	}
+	api.EscreverJSON(w, http.StatusOK, pagina)
+}
+
+// GET /concursos-processados, com os mesmos parâmetros e mais ?lote=
+func listarProcessadosHandler(w http.ResponseWriter, r *http.Request) {
+	filtro, campos, err := lerFiltroConsulta(r, camposProcessado)
+	if err != nil {
+		api.EscreverErro(w, r, "", err)
+		return
+	}
+
+	service := services.NewConcursoService(slog.Default())
+
+	pagina, err := service.ListarProcessados(r.Context(), filtro, campos)
+	if err != nil {
+		api.EscreverErro(w, r, "Erro ao consultar concursos processados", err)
+		return
+	}
+	api.EscreverJSON(w, http.StatusOK, pagina)
+}
+
+// lerFiltroConsulta valida os parâmetros da query. ordem aceita "-" para
+// decrescente (ex.: ordem=-data_prova). Erros são *api.Erro (400).
+func lerFiltroConsulta(r *http.Request, camposPermitidos []string) (models.FiltroConsulta, []string, error) {
+	q := r.URL.Query()
+	filtro := models.FiltroConsulta{
+		Data:        q.Get("data"),
+		Status:      q.Get("status"),
+		PrefixoNome: q.Get("nome"),
+		Lote:        q.Get("lote"),
+	}
+
+	if err := api.DataOpcional("data", filtro.Data); err != nil {
+		return filtro, nil, err
+	}
+
+	ordem := q.Get("ordem")
+	if strings.HasPrefix(ordem, "-") {
+		filtro.Decrescente = true
+		ordem = ordem[1:]
+	}
+	ordem, err := api.Opcao("ordem", ordem, "", models.CamposOrdenacao...)
+	if err != nil {
+		return filtro, nil, err
+	}
+	filtro.Ordem = ordem
+
+	if filtro.Limite, err = api.Inteiro("limite", q.Get("limite"), limitePadrao, 1, limiteMaximo); err != nil {
+		return filtro, nil, err
+	}
+
+	if cursor := q.Get("cursor"); cursor != "" {
+		c, err := models.DecodificarCursor(cursor)
+		if err != nil {
+			return filtro, nil, api.ErroValidacao(err.Error(), map[string]string{"campo": "cursor"})
+		}
+		filtro.Cursor = c
+	}
+
+	var campos []string
+	if lista := q.Get("campos"); lista != "" {
+		for _, campo := range strings.Split(lista, ",") {
+			campo = strings.TrimSpace(campo)
+			if !contem(camposPermitidos, campo) {
+				return filtro, nil, api.ErroValidacao(fmt.Sprintf("campo inválido: %s", campo), map[string]interface{}{"campo": "campos", "valor": campo, "opcoes": camposPermitidos})
+			}
+			campos = append(campos, campo)
+		}
+	}
+
+	return filtro, campos, nil
+}
+
+func contem(lista []string, valor string) bool {
+	for _, item := range lista {
+		if item == valor {
+			return true
+		}
+	}
+	return false
+}
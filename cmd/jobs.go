@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
+
+	"concurso-go-app/internal/api"
+	"concurso-go-app/internal/jobs"
+	"concurso-go-app/internal/logger"
+	"concurso-go-app/internal/metricas"
+	"concurso-go-app/internal/services"
+)
+
+// gerenciadorJobs acompanha as execuções de /start, /extrair e /consumir
+var gerenciadorJobs = jobs.NovoGerenciador()
+
+// HeaderJobID devolve o id do job, para cancelá-lo com DELETE /jobs/{id}
+const HeaderJobID = "X-Job-ID"
+
+// executarJob roda a etapa como job. Por padrão a requisição espera o fim e,
+// se o cliente desconectar, o job é cancelado. Com ?async=true responde 202
+// com o job na hora e ele segue até terminar ou até DELETE /jobs/{id}.
+func executarJob(w http.ResponseWriter, r *http.Request, tipo string, data string, contexto string, executar func(ctx context.Context) (map[string]interface{}, error)) {
+	async, err := api.Opcao("async", r.URL.Query().Get("async"), "false", "true", "false")
+	if err != nil {
+		api.EscreverErro(w, r, "", err)
+		return
+	}
+
+	base := r.Context()
+	if async == "true" {
+		base = context.WithoutCancel(base)
+	}
+	job, ctx, err := gerenciadorJobs.Iniciar(base, tipo, data)
+	if err != nil {
+		api.EscreverErro(w, r, "", api.ErroIndisponivel(err.Error()))
+		return
+	}
+	w.Header().Set(HeaderJobID, job.ID)
+	api.SpanDe(r).SetAttributes(attribute.String("job_id", job.ID))
+	ctx = logger.ComAtributos(ctx, slog.String(logger.ChaveJobID, job.ID))
+
+	if async == "true" {
+		go func() {
+			response, err := executar(ctx)
+			finalizarJob(job, response, err)
+		}()
+		api.EscreverJSON(w, http.StatusAccepted, map[string]interface{}{
+			"mensagem": "Job iniciado",
+			"job":      job,
+		})
+		return
+	}
+
+	response, err := executar(ctx)
+	finalizarJob(job, response, err)
+	if err != nil {
+		api.EscreverErro(w, r, contexto, err)
+		return
+	}
+	response["job_id"] = job.ID
+	api.EscreverJSON(w, http.StatusOK, response)
+}
+
+// finalizarJob grava o resultado do job e conta as falhas por categoria
+// (cancelamentos não são falha)
+func finalizarJob(job jobs.Job, response map[string]interface{}, err error) {
+	gerenciadorJobs.Finalizar(job.ID, response, err)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		metricas.Falhas.WithLabelValues(job.Tipo, services.CategoriaErro(err)).Inc()
+	}
+}
+
+// GET /jobs
+func listarJobsHandler(w http.ResponseWriter, r *http.Request) {
+	api.EscreverJSON(w, http.StatusOK, map[string]interface{}{"jobs": gerenciadorJobs.Listar()})
+}
+
+// GET /jobs/{id}
+func obterJobHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := gerenciadorJobs.Obter(mux.Vars(r)["id"])
+	if !ok {
+		api.EscreverErro(w, r, "", api.ErroNaoEncontrado(jobs.ErrJobNaoEncontrado.Error()))
+		return
+	}
+	api.EscreverJSON(w, http.StatusOK, job)
+}
+
+// DELETE /jobs/{id} pede o cancelamento; o status muda para "cancelado"
+// quando a etapa em andamento parar
+func cancelarJobHandler(w http.ResponseWriter, r *http.Request) {
+	job, err := gerenciadorJobs.Cancelar(mux.Vars(r)["id"])
+	switch {
+	case errors.Is(err, jobs.ErrJobNaoEncontrado):
+		api.EscreverErro(w, r, "", api.ErroNaoEncontrado(err.Error()))
+		return
+	case errors.Is(err, jobs.ErrJobFinalizado):
+		api.EscreverErro(w, r, "", api.ErroConflito(err.Error(), map[string]string{"id": job.ID, "status": job.Status}))
+		return
+	}
+
+	api.EscreverJSON(w, http.StatusAccepted, map[string]interface{}{
+		"mensagem": "Cancelamento solicitado",
+		"job":      job,
+	})
+}
+
+// intervaloKeepAlive é o intervalo dos comentários SSE que mantêm a conexão
+// aberta em proxies enquanto o job não publica nada
+const intervaloKeepAlive = 15 * time.Second
+
+// GET /jobs/{id}/eventos acompanha o job por Server-Sent Events: fases,
+// progresso, header/footer encontrados e, por último, o evento "resultado",
+// depois do qual o stream é fechado. Com o header Last-Event-ID (reconexão do
+// EventSource) só os eventos seguintes são enviados.
+func eventosJobHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	ultimo := 0
+	if valor := r.Header.Get("Last-Event-ID"); valor != "" {
+		n, err := strconv.Atoi(valor)
+		if err != nil || n < 0 {
+			api.EscreverErro(w, r, "", api.ErroValidacao("Last-Event-ID inválido: "+valor, nil))
+			return
+		}
+		ultimo = n
+	}
+	if _, ok := gerenciadorJobs.Obter(id); !ok {
+		api.EscreverErro(w, r, "", api.ErroNaoEncontrado(jobs.ErrJobNaoEncontrado.Error()))
+		return
+	}
+
+	// O stream dura o job inteiro: sem o HTTP_WRITE_TIMEOUT do servidor
+	controle := http.NewResponseController(w)
+	controle.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	controle.Flush()
+
+	keepAlive := time.NewTicker(intervaloKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		eventos, mudou, finalizado, err := gerenciadorJobs.Eventos(id, ultimo)
+		if err != nil {
+			return
+		}
+		for _, evento := range eventos {
+			corpo, err := json.Marshal(evento)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "erro ao serializar evento", "erro", err)
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", evento.ID, evento.Tipo, corpo)
+			ultimo = evento.ID
+		}
+		if err := controle.Flush(); err != nil || finalizado {
+			return
+		}
+
+		select {
+		case <-mudou:
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
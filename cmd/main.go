@@ -1,144 +1,268 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 
+	"concurso-go-app/internal/api"
+	"concurso-go-app/internal/autenticacao"
 	"concurso-go-app/internal/database"
+	"concurso-go-app/internal/jobs"
+	"concurso-go-app/internal/logger"
+	"concurso-go-app/internal/metricas"
+	"concurso-go-app/internal/migrations"
+	"concurso-go-app/internal/models"
+	"concurso-go-app/internal/rastreamento"
 	"concurso-go-app/internal/services"
 )
 
 func main() {
 	// Carregar variáveis de ambiente
-	if err := godotenv.Load(); err != nil {
-		log.Println("Arquivo .env não encontrado, usando variáveis do sistema")
+	erroEnv := godotenv.Load()
+
+	// Logger estruturado (LOG_LEVEL, LOG_FORMAT); log.Printf dos pacotes também passa por ele
+	registro, err := logger.Novo()
+	if err != nil {
+		slog.Error("erro na configuração do log", "erro", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(registro)
+	if erroEnv != nil {
+		slog.Info("arquivo .env não encontrado, usando variáveis do sistema")
+	}
+
+	// Tracing (TRACING_EXPORTER); sem exportador o contexto só é propagado
+	finalizarRastreamento, err := rastreamento.Iniciar(context.Background())
+	if err != nil {
+		fatal("erro ao configurar tracing", err)
 	}
 
 	// Inicializar banco de dados
 	if err := database.InitDB(); err != nil {
-		log.Fatalf("Erro ao conectar ao banco: %v", err)
+		fatal("erro ao conectar ao banco", err)
 	}
 
-	// Criar tabelas automaticamente na inicialização
-	service := services.NewConcursoService()
-	if err := service.CriarTabelas(); err != nil {
-		log.Printf("Aviso: Erro ao criar tabelas na inicialização: %v", err)
-	} else {
-		log.Println("Tabelas verificadas/criadas na inicialização")
+	// Aplicar migrations pendentes (com lock, seguro com várias instâncias)
+	versao, err := migrations.UltimaVersao(database.Driver)
+	if err != nil {
+		fatal("erro ao carregar migrations", err)
+	}
+	if err := migrations.Aplicar(database.DB, database.Driver, versao); err != nil {
+		fatal("erro ao aplicar migrations", err)
+	}
+	slog.Info("schema do banco atualizado", "versao", versao)
+
+	// Autenticação (API_KEYS, JWT_HS256_SECRET, JWT_JWKS_FILE)
+	autenticador, err := autenticacao.Carregar()
+	if err != nil {
+		fatal("erro na configuração da autenticação", err)
+	}
+	if !autenticador.Habilitado() {
+		slog.Warn("autenticação desabilitada (AUTH_ENABLED=false): todas as chamadas têm papel admin")
 	}
 
 	// Configurar rotas
+	r := novoRoteador(autenticador)
+
+	// Iniciar servidor
+	port := os.Getenv("API_PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	srv, err := novoServidor(":"+port, r)
+	if err != nil {
+		fatal("erro na configuração do servidor", err)
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("erro no servidor", err)
+		}
+	}()
+	slog.Info("servidor iniciado", "porta", port)
+
+	// SIGINT/SIGTERM: para de aceitar trabalho e drena os jobs
+	sinal, parar := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-sinal.Done()
+	parar()
+	encerrar(srv, finalizarRastreamento)
+}
+
+// novoRoteador configura as rotas. Cada rota exige um papel: leitura
+// consulta, operador extrai/consome, admin popula e limpa. Health e métricas
+// ficam abertos.
+func novoRoteador(autenticador *autenticacao.Autenticador) *mux.Router {
 	r := mux.NewRouter()
+	r.Use(api.RequestID)
+	r.Use(api.Rastreamento)
+	r.Use(api.Autenticacao(autenticador))
 
 	// Endpoint para popular dados
-	r.HandleFunc("/start", startHandler).Methods("POST")
+	r.Handle("/start", api.Exigir(autenticacao.PapelAdmin, startHandler)).Methods("POST")
 
 	// Endpoint para extrair registros e enviar para Kafka
-	r.HandleFunc("/extrair/{data}", extrairHandler).Methods("POST")
+	r.Handle("/extrair/{data}", api.Exigir(autenticacao.PapelOperador, extrairHandler)).Methods("POST")
 
 	// Endpoint para consumir registros do Kafka
-	r.HandleFunc("/consumir/{data}", consumirHandler).Methods("POST")
+	r.Handle("/consumir/{data}", api.Exigir(autenticacao.PapelOperador, consumirHandler)).Methods("POST")
 
 	// Endpoint para limpar tópico Kafka
-	r.HandleFunc("/limpar", limparKafkaHandler).Methods("POST")
+	r.Handle("/limpar", api.Exigir(autenticacao.PapelAdmin, limparKafkaHandler)).Methods("POST")
 
-	// Iniciar servidor
-	port := os.Getenv("API_PORT")
-	if port == "" {
-		port = "8080"
-	}
+	// Endpoints de consulta das tabelas de origem e processada
+	r.Handle("/concursos", api.Exigir(autenticacao.PapelLeitura, listarConcursosHandler)).Methods("GET")
+	r.Handle("/concursos-processados", api.Exigir(autenticacao.PapelLeitura, listarProcessadosHandler)).Methods("GET")
 
-	log.Printf("Servidor iniciado na porta %s", port)
-	log.Fatal(http.ListenAndServe(":"+port, r))
-}
+	// Endpoint de reconciliação entre origem e destino
+	r.Handle("/reconciliacao", api.Exigir(autenticacao.PapelLeitura, reconciliacaoHandler)).Methods("GET")
 
-func startHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+	// Relatório de resultados por data/semana/mês e status
+	r.Handle("/relatorios/resultados", api.Exigir(autenticacao.PapelLeitura, relatorioResultadosHandler)).Methods("GET")
 
-	service := services.NewConcursoService()
+	// Liveness e readiness (banco, Kafka e versão do schema)
+	r.HandleFunc("/healthz", healthzHandler).Methods("GET")
+	r.HandleFunc("/readyz", readyzHandler).Methods("GET")
 
-	// Popular dados (as tabelas são criadas automaticamente se não existirem)
-	if err := service.PopularDados(); err != nil {
-		http.Error(w, `{"erro": "Erro ao popular dados: `+err.Error()+`"}`, http.StatusInternalServerError)
-		return
-	}
+	// Métricas do pipeline no formato do Prometheus
+	r.Handle("/metrics", metricas.Handler()).Methods("GET")
 
-	response := map[string]string{
-		"mensagem": "Dados populados com sucesso (3100 registros)",
-	}
-	json.NewEncoder(w).Encode(response)
+	// Acompanhamento e cancelamento dos jobs de /start, /extrair e /consumir
+	r.Handle("/jobs", api.Exigir(autenticacao.PapelLeitura, listarJobsHandler)).Methods("GET")
+	r.Handle("/jobs/{id}", api.Exigir(autenticacao.PapelLeitura, obterJobHandler)).Methods("GET")
+	r.Handle("/jobs/{id}", api.Exigir(autenticacao.PapelOperador, cancelarJobHandler)).Methods("DELETE")
+	r.Handle("/jobs/{id}/eventos", api.Exigir(autenticacao.PapelLeitura, eventosJobHandler)).Methods("GET")
+
+	return r
 }
 
-func extrairHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+// fatal registra o erro e encerra o processo
+func fatal(mensagem string, err error) {
+	slog.Error(mensagem, "erro", err)
+	os.Exit(1)
+}
 
-	vars := mux.Vars(r)
-	data := vars["data"]
+// tamanhoMaximoCorpo limita o corpo do POST /start (a configuração cabe com folga)
+const tamanhoMaximoCorpo = 1 << 20
 
-	// Validar formato da data
-	if len(data) != 10 || data[4] != '-' || data[7] != '-' {
-		http.Error(w, `{"erro": "Formato de data inválido. Use YYYY-MM-DD"}`, http.StatusBadRequest)
+func startHandler(w http.ResponseWriter, r *http.Request) {
+	// Corpo vazio gera a massa histórica; com corpo, campos omitidos usam os
+	// padrões, sem as exceções do dia 01
+	config := models.ConfigGeracaoPadrao()
+	corpo, err := io.ReadAll(http.MaxBytesReader(w, r.Body, tamanhoMaximoCorpo))
+	if err != nil {
+		var erroTamanho *http.MaxBytesError
+		if errors.As(err, &erroTamanho) {
+			api.EscreverErro(w, r, "", api.ErroCorpoGrande(fmt.Sprintf("Corpo da requisição maior que %d bytes", tamanhoMaximoCorpo)))
+			return
+		}
+		api.EscreverErro(w, r, "", api.ErroValidacao("Erro ao ler corpo da requisição", err.Error()))
 		return
 	}
-
-	service := services.NewConcursoService()
-
-	if err := service.ExtrairRegistros(data); err != nil {
-		http.Error(w, `{"erro": "Erro ao extrair registros: `+err.Error()+`"}`, http.StatusInternalServerError)
+	if len(bytes.TrimSpace(corpo)) > 0 {
+		config.Dias = nil
+		if err := json.Unmarshal(corpo, &config); err != nil {
+			api.EscreverErro(w, r, "", api.ErroValidacao("JSON inválido", err.Error()))
+			return
+		}
+	}
+	if err := config.Validar(); err != nil {
+		api.EscreverErro(w, r, "", api.ErroValidacao(err.Error(), nil))
 		return
 	}
 
-	response := map[string]string{
-		"mensagem": "Registros extraídos e enviados para Kafka com sucesso",
-		"data":     data,
-	}
-	json.NewEncoder(w).Encode(response)
+	service := services.NewConcursoService(slog.Default())
+
+	// Popular dados (as tabelas são criadas pelas migrations na inicialização)
+	executarJob(w, r, jobs.TipoGeracao, "", "Erro ao popular dados", func(ctx context.Context) (map[string]interface{}, error) {
+		resultado, err := service.PopularDados(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"mensagem":  fmt.Sprintf("Dados populados com sucesso (%d registros)", resultado.TotalInserido),
+			"resultado": resultado,
+		}, nil
+	})
 }
 
-func consumirHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
-	vars := mux.Vars(r)
-	data := vars["data"]
+func extrairHandler(w http.ResponseWriter, r *http.Request) {
+	data := mux.Vars(r)["data"]
+	if _, err := api.Data("data", data); err != nil {
+		api.EscreverErro(w, r, "", err)
+		return
+	}
 
-	// Validar formato da data
-	if len(data) != 10 || data[4] != '-' || data[7] != '-' {
-		http.Error(w, `{"erro": "Formato de data inválido. Use YYYY-MM-DD"}`, http.StatusBadRequest)
+	// ?falhas=sem_footer,duplicar publica o lote com defeitos (só com FAULT_INJECTION_ENABLED)
+	falhas, err := services.ParseFalhas(r.URL.Query().Get("falhas"))
+	if err != nil {
+		api.EscreverErro(w, r, "", api.ErroValidacao(err.Error(), map[string]string{"campo": "falhas"}))
+		return
+	}
+	if len(falhas) > 0 && !services.InjecaoFalhasHabilitada() {
+		api.EscreverErro(w, r, "", api.ErroProibido("Injeção de falhas desabilitada (FAULT_INJECTION_ENABLED)"))
 		return
 	}
 
-	service := services.NewConcursoService()
+	service := services.NewConcursoService(slog.Default())
+
+	executarJob(w, r, jobs.TipoExtracao, data, "Erro ao extrair registros", func(ctx context.Context) (map[string]interface{}, error) {
+		if err := service.ExtrairRegistros(ctx, data, falhas); err != nil {
+			return nil, err
+		}
+		response := map[string]interface{}{
+			"mensagem": "Registros extraídos e enviados para Kafka com sucesso",
+			"data":     data,
+		}
+		if len(falhas) > 0 {
+			response["falhas_injetadas"] = falhas.Lista()
+		}
+		return response, nil
+	})
+}
 
-	if err := service.ConsumirRegistros(data); err != nil {
-		http.Error(w, `{"erro": "Erro ao consumir registros: `+err.Error()+`"}`, http.StatusInternalServerError)
+func consumirHandler(w http.ResponseWriter, r *http.Request) {
+	data := mux.Vars(r)["data"]
+	if _, err := api.Data("data", data); err != nil {
+		api.EscreverErro(w, r, "", err)
 		return
 	}
 
-	response := map[string]string{
-		"mensagem": "Registros consumidos e processados com sucesso",
-		"data":     data,
-	}
-	json.NewEncoder(w).Encode(response)
+	service := services.NewConcursoService(slog.Default())
+
+	executarJob(w, r, jobs.TipoConsumo, data, "Erro ao consumir registros", func(ctx context.Context) (map[string]interface{}, error) {
+		if err := service.ConsumirRegistros(ctx, data); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"mensagem": "Registros consumidos e processados com sucesso",
+			"data":     data,
+		}, nil
+	})
 }
 
 func limparKafkaHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-
 	// Usar o service para limpar (que tem a lógica melhorada)
-	service := services.NewConcursoService()
+	service := services.NewConcursoService(slog.Default())
 
-	if err := service.LimparTopicoKafka(); err != nil {
-		http.Error(w, `{"erro": "Erro ao limpar Kafka: `+err.Error()+`"}`, http.StatusInternalServerError)
+	if err := service.LimparTopicoKafka(r.Context()); err != nil {
+		api.EscreverErro(w, r, "Erro ao limpar Kafka", err)
 		return
 	}
 
 	response := map[string]string{
 		"mensagem": "Tópico Kafka limpo com sucesso",
 	}
-	json.NewEncoder(w).Encode(response)
+	api.EscreverJSON(w, http.StatusOK, response)
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"concurso-go-app/internal/autenticacao"
+	"concurso-go-app/internal/database"
+)
+
+// Com autenticação habilitada, health e métricas respondem sem credencial e
+// as demais rotas não
+func TestRotasAbertas(t *testing.T) {
+	t.Setenv("AUTH_ENABLED", "true")
+	t.Setenv("API_KEYS", "painel:leitura:chave-do-painel-0001")
+	t.Setenv("JWT_HS256_SECRET", "")
+	t.Setenv("JWT_JWKS_FILE", "")
+	aut, err := autenticacao.Carregar()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// /readyz precisa de um banco; o Kafka fica inacessível e ele responde 503
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "teste.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	database.DB, database.Driver = db, database.DriverSQLite
+	t.Cleanup(func() { database.DB, database.Driver = nil, "" })
+	t.Setenv("KAFKA_BROKERS", "127.0.0.1:1")
+	t.Setenv("READINESS_TIMEOUT", "500ms")
+
+	roteador := novoRoteador(aut)
+	casos := []struct {
+		metodo string
+		rota   string
+		aberta bool
+	}{
+		{http.MethodGet, "/healthz", true},
+		{http.MethodGet, "/readyz", true},
+		{http.MethodGet, "/metrics", true},
+		{http.MethodGet, "/concursos", false},
+		{http.MethodGet, "/jobs", false},
+		{http.MethodPost, "/extrair/2024-01-01", false},
+		{http.MethodPost, "/start", false},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.metodo+" "+caso.rota, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			roteador.ServeHTTP(w, httptest.NewRequest(caso.metodo, caso.rota, nil))
+
+			negado := w.Code == http.StatusUnauthorized || w.Code == http.StatusForbidden
+			if caso.aberta && negado {
+				t.Fatalf("rota aberta respondeu %d sem credencial", w.Code)
+			}
+			if !caso.aberta && w.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d sem credencial, esperado 401", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+
+	"concurso-go-app/internal/database"
+	"concurso-go-app/internal/migrations"
+)
+
+// Uso:
+//
+//	go run ./cmd/migrate up              aplica todas as migrations pendentes
+//	go run ./cmd/migrate up -alvo 3      aplica até a versão 3 (nunca desfaz)
+//	go run ./cmd/migrate down -alvo 1    desfaz as migrations acima da versão 1
+//	go run ./cmd/migrate status          mostra a versão atual e a mais recente
+func main() {
+	if len(os.Args) < 2 {
+		uso()
+	}
+	comando := os.Args[1]
+
+	flags := flag.NewFlagSet(comando, flag.ExitOnError)
+	alvo := flags.Int("alvo", -1, "versão alvo (up: padrão a mais recente; down: obrigatória)")
+	flags.Parse(os.Args[2:])
+
+	// Carregar variáveis de ambiente
+	if err := godotenv.Load(); err != nil {
+		log.Println("Arquivo .env não encontrado, usando variáveis do sistema")
+	}
+
+	if err := database.InitDB(); err != nil {
+		log.Fatalf("Erro ao conectar ao banco: %v", err)
+	}
+
+	ultima, err := migrations.UltimaVersao(database.Driver)
+	if err != nil {
+		log.Fatalf("Erro ao carregar migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	atual, err := migrations.VersaoAtual(ctx, database.DB)
+	if err != nil {
+		log.Fatalf("Erro ao consultar versão: %v", err)
+	}
+
+	switch comando {
+	case "up":
+		if *alvo < 0 {
+			*alvo = ultima
+		}
+		// up nunca desfaz: um -alvo errado não pode apagar tabelas
+		if *alvo < atual {
+			log.Fatalf("Schema na versão %d, acima do alvo %d: para desfazer use down -alvo %d", atual, *alvo, *alvo)
+		}
+		if err := migrations.Aplicar(database.DB, database.Driver, *alvo); err != nil {
+			log.Fatalf("Erro ao aplicar migrations: %v", err)
+		}
+	case "down":
+		if *alvo < 0 {
+			log.Fatal("Informe a versão alvo com -alvo (0 desfaz todas)")
+		}
+		if *alvo > atual {
+			log.Fatalf("Schema na versão %d, abaixo do alvo %d: para aplicar use up -alvo %d", atual, *alvo, *alvo)
+		}
+		if err := migrations.Aplicar(database.DB, database.Driver, *alvo); err != nil {
+			log.Fatalf("Erro ao desfazer migrations: %v", err)
+		}
+	case "status":
+	default:
+		uso()
+	}
+
+	atual, err = migrations.VersaoAtual(ctx, database.DB)
+	if err != nil {
+		log.Fatalf("Erro ao consultar versão: %v", err)
+	}
+	fmt.Printf("Versão do schema: %d (mais recente: %d)\n", atual, ultima)
+}
+
+func uso() {
+	fmt.Fprintln(os.Stderr, "uso: migrate up|down|status [-alvo versão]")
+	os.Exit(2)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+
+	"concurso-go-app/internal/api"
+	"concurso-go-app/internal/services"
+)
+
+// maxDiasReconciliacao limita o intervalo de uma consulta
+const maxDiasReconciliacao = 366
+
+// GET /reconciliacao?de=YYYY-MM-DD&ate=YYYY-MM-DD
+func reconciliacaoHandler(w http.ResponseWriter, r *http.Request) {
+	de, ate := r.URL.Query().Get("de"), r.URL.Query().Get("ate")
+	if err := api.Intervalo(de, ate, maxDiasReconciliacao); err != nil {
+		api.EscreverErro(w, r, "", err)
+		return
+	}
+
+	service := services.NewConcursoService(slog.Default())
+
+	datas, err := service.Reconciliar(r.Context(), de, ate)
+	if err != nil {
+		api.EscreverErro(w, r, "Erro na reconciliação", err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"de":    de,
+		"ate":   ate,
+		"datas": datas,
+	}
+	api.EscreverJSON(w, http.StatusOK, response)
+}
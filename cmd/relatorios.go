@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"concurso-go-app/internal/api"
+	"concurso-go-app/internal/models"
+	"concurso-go-app/internal/services"
+)
+
+// GET /relatorios/resultados?de=&ate=&agrupar=dia|semana|mes&formato=json|csv
+// (formato também pode vir do Accept: text/csv)
+func relatorioResultadosHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	formatoPadrao := "json"
+	if strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		formatoPadrao = "csv"
+	}
+	formato, err := api.Opcao("formato", q.Get("formato"), formatoPadrao, "json", "csv")
+	if err != nil {
+		api.EscreverErro(w, r, "", err)
+		return
+	}
+
+	de, ate := q.Get("de"), q.Get("ate")
+	if err := api.Intervalo(de, ate, 0); err != nil {
+		api.EscreverErro(w, r, "", err)
+		return
+	}
+
+	agrupar, err := api.Opcao("agrupar", q.Get("agrupar"), models.AgruparDia, models.AgruparDia, models.AgruparSemana, models.AgruparMes)
+	if err != nil {
+		api.EscreverErro(w, r, "", err)
+		return
+	}
+
+	service := services.NewConcursoService(slog.Default())
+
+	relatorio, err := service.RelatorioResultados(r.Context(), de, ate, agrupar)
+	if err != nil {
+		api.EscreverErro(w, r, "Erro ao gerar relatório", err)
+		return
+	}
+
+	if formato == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="resultados_%s_%s_%s.csv"`, de, ate, agrupar))
+		if err := services.EscreverRelatorioCSV(w, relatorio); err != nil {
+			slog.WarnContext(r.Context(), "erro ao escrever CSV", "erro", err)
+		}
+		return
+	}
+	api.EscreverJSON(w, http.StatusOK, relatorio)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"concurso-go-app/internal/api"
+	"concurso-go-app/internal/database"
+	"concurso-go-app/internal/kafka"
+	"concurso-go-app/internal/migrations"
+)
+
+// Status de cada dependência no /readyz
+const (
+	statusOK   = "ok"
+	statusErro = "erro"
+)
+
+// inicioProcesso é usado no uptime do /healthz
+var inicioProcesso = time.Now()
+
+// Dependencia é o resultado da verificação de um componente
+type Dependencia struct {
+	Status     string                 `json:"status"`
+	LatenciaMs float64                `json:"latencia_ms"`
+	Erro       string                 `json:"erro,omitempty"`
+	Detalhes   map[string]interface{} `json:"detalhes,omitempty"`
+}
+
+// GET /healthz: o processo está de pé (não consulta dependências)
+func healthzHandler(w http.ResponseWriter, r *http.Request) {
+	api.EscreverJSON(w, http.StatusOK, map[string]interface{}{
+		"status": statusOK,
+		"uptime": time.Since(inicioProcesso).Round(time.Second).String(),
+	})
+}
+
+// GET /readyz: banco, Kafka e versão do schema, verificados em paralelo com
+// limite de READINESS_TIMEOUT (padrão 2s). 503 se algum estiver com erro.
+func readyzHandler(w http.ResponseWriter, r *http.Request) {
+	limite, err := duracaoEnv("READINESS_TIMEOUT", 2*time.Second)
+	if err != nil {
+		limite = 2 * time.Second
+	}
+	ctx, cancelar := context.WithTimeout(r.Context(), limite)
+	defer cancelar()
+
+	verificacoes := map[string]func(context.Context) (map[string]interface{}, error){
+		"banco":      verificarBanco,
+		"kafka":      verificarKafka,
+		"migrations": verificarMigrations,
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	dependencias := make(map[string]Dependencia, len(verificacoes))
+	for nome, verificar := range verificacoes {
+		wg.Add(1)
+		go func(nome string, verificar func(context.Context) (map[string]interface{}, error)) {
+			defer wg.Done()
+			inicio := time.Now()
+			detalhes, err := verificar(ctx)
+			dependencia := Dependencia{
+				Status:     statusOK,
+				LatenciaMs: float64(time.Since(inicio).Microseconds()) / 1000,
+				Detalhes:   detalhes,
+			}
+			if err != nil {
+				dependencia.Status = statusErro
+				dependencia.Erro = err.Error()
+			}
+			mu.Lock()
+			dependencias[nome] = dependencia
+			mu.Unlock()
+		}(nome, verificar)
+	}
+	wg.Wait()
+
+	status, codigo := statusOK, http.StatusOK
+	for _, dependencia := range dependencias {
+		if dependencia.Status != statusOK {
+			status, codigo = statusErro, http.StatusServiceUnavailable
+		}
+	}
+	api.EscreverJSON(w, codigo, map[string]interface{}{
+		"status":       status,
+		"dependencias": dependencias,
+	})
+}
+
+func verificarBanco(ctx context.Context) (map[string]interface{}, error) {
+	detalhes := map[string]interface{}{"driver": database.Driver}
+	if err := database.DB.PingContext(ctx); err != nil {
+		return detalhes, err
+	}
+	estatisticas := database.DB.Stats()
+	detalhes["conexoes_abertas"] = estatisticas.OpenConnections
+	detalhes["conexoes_em_uso"] = estatisticas.InUse
+	return detalhes, nil
+}
+
+func verificarKafka(ctx context.Context) (map[string]interface{}, error) {
+	detalhes := map[string]interface{}{"bootstrap": kafka.Brokers()}
+	brokers, err := kafka.VerificarBrokers(ctx)
+	if err != nil {
+		return detalhes, err
+	}
+	detalhes["brokers"] = brokers
+	return detalhes, nil
+}
+
+// verificarMigrations falha se o schema estiver atrás da última migration
+// embutida no binário (outra instância ainda migrando, por exemplo)
+func verificarMigrations(ctx context.Context) (map[string]interface{}, error) {
+	esperada, err := migrations.UltimaVersao(database.Driver)
+	if err != nil {
+		return nil, err
+	}
+	atual, err := migrations.VersaoAtual(ctx, database.DB)
+	detalhes := map[string]interface{}{"versao": atual, "esperada": esperada}
+	if err != nil {
+		return detalhes, err
+	}
+	if atual < esperada {
+		return detalhes, fmt.Errorf("schema na versão %d, esperada %d", atual, esperada)
+	}
+	return detalhes, nil
+}
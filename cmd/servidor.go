@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"concurso-go-app/internal/database"
+	"concurso-go-app/internal/kafka"
+)
+
+// Após a carência, os jobs cancelados têm este tempo para gravar o status
+// "cancelado" antes de o banco ser fechado
+const esperaCancelamento = 10 * time.Second
+
+// novoServidor aplica os timeouts HTTP_READ_TIMEOUT, HTTP_WRITE_TIMEOUT e
+// HTTP_IDLE_TIMEOUT. O de escrita é longo porque /extrair e /consumir
+// respondem só no fim; para execuções maiores use ?async=true.
+func novoServidor(endereco string, handler http.Handler) (*http.Server, error) {
+	leitura, err := duracaoEnv("HTTP_READ_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	escrita, err := duracaoEnv("HTTP_WRITE_TIMEOUT", 30*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	ocioso, err := duracaoEnv("HTTP_IDLE_TIMEOUT", 2*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Server{
+		Addr:              endereco,
+		Handler:           handler,
+		ReadHeaderTimeout: leitura,
+		ReadTimeout:       leitura,
+		WriteTimeout:      escrita,
+		IdleTimeout:       ocioso,
+	}, nil
+}
+
+// encerrar recusa novos jobs, fecha o listener e espera os jobs por até
+// SHUTDOWN_GRACE_PERIOD (padrão 30s). Os que sobrarem são cancelados. Por
+// fim fecha os clientes Kafka ainda abertos, fecha o pool do banco e envia os spans pendentes.
+func encerrar(srv *http.Server, finalizarRastreamento func(context.Context) error) {
+	carencia, err := duracaoEnv("SHUTDOWN_GRACE_PERIOD", 30*time.Second)
+	if err != nil {
+		slog.Warn("SHUTDOWN_GRACE_PERIOD inválido, usando 30s", "erro", err)
+		carencia = 30 * time.Second
+	}
+	slog.Info("encerrando: aguardando os jobs em andamento", "carencia", carencia)
+
+	gerenciadorJobs.Encerrar()
+	ctx, cancelar := context.WithTimeout(context.Background(), carencia)
+	defer cancelar()
+
+	// Shutdown espera as requisições abertas, inclusive os jobs síncronos
+	resultadoShutdown := make(chan error, 1)
+	go func() {
+		resultadoShutdown <- srv.Shutdown(ctx)
+	}()
+
+	if !gerenciadorJobs.Aguardar(ctx) {
+		total := gerenciadorJobs.CancelarTodos()
+		slog.Warn("carência esgotada, jobs cancelados", "jobs", total)
+
+		ctxCancelamento, cancelarEspera := context.WithTimeout(context.Background(), esperaCancelamento)
+		defer cancelarEspera()
+		if !gerenciadorJobs.Aguardar(ctxCancelamento) {
+			slog.Warn("jobs não pararam após o cancelamento; encerrando assim mesmo", "espera", esperaCancelamento)
+		}
+	}
+
+	if err := <-resultadoShutdown; err != nil {
+		slog.Warn("conexões ainda abertas após a carência, fechando", "erro", err)
+		srv.Close()
+	}
+
+	// Fecha os clientes Kafka dos jobs que não terminaram a tempo; o Close do
+	// produtor síncrono espera as mensagens pendentes
+	kafka.CloseProducer()
+	kafka.CloseConsumer()
+
+	if err := database.DB.Close(); err != nil {
+		slog.Error("erro ao fechar o banco", "erro", err)
+	}
+
+	// Envia os spans que ainda estão no buffer do exportador
+	ctxRastreamento, cancelarRastreamento := context.WithTimeout(context.Background(), esperaCancelamento)
+	defer cancelarRastreamento()
+	if err := finalizarRastreamento(ctxRastreamento); err != nil {
+		slog.Error("erro ao enviar os últimos spans", "erro", err)
+	}
+	slog.Info("servidor encerrado")
+}
+
+func duracaoEnv(nome string, padrao time.Duration) (time.Duration, error) {
+	valor := os.Getenv(nome)
+	if valor == "" {
+		return padrao, nil
+	}
+	duracao, err := time.ParseDuration(valor)
+	if err != nil {
+		return 0, fmt.Errorf("%s inválido: %v", nome, err)
+	}
+	return duracao, nil
+}
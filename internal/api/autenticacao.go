@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
+
+	"concurso-go-app/internal/autenticacao"
+	"concurso-go-app/internal/logger"
+)
+
+// Autenticacao identifica o principal de cada requisição (X-API-Key ou JWT)
+// e o guarda no contexto, nos atributos de log e no span. Não bloqueia nada:
+// quem exige papel é Exigir, para /healthz, /readyz e /metrics seguirem
+// abertos. Deve vir depois de RequestID e Rastreamento.
+func Autenticacao(aut *autenticacao.Autenticador) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			principal, err := aut.Autenticar(r)
+			if err != nil {
+				ctx = context.WithValue(ctx, chaveErroAutenticacao, err)
+			} else {
+				ctx = autenticacao.ComPrincipal(ctx, principal)
+				ctx = logger.ComAtributos(ctx, slog.String(logger.ChavePrincipal, principal.Nome))
+				SpanDe(r).SetAttributes(attribute.String("enduser.id", principal.Nome), attribute.String("enduser.role", principal.Papel.String()))
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Exigir só deixa passar principais com o papel exigido (401 sem credencial
+// válida, 403 com papel insuficiente) e registra a linha de auditoria da
+// chamada, com o principal, a rota e o status devolvido
+func Exigir(papel autenticacao.Papel, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inicio := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+		principal, autenticado := autenticacao.PrincipalDe(r.Context())
+		switch {
+		case !autenticado:
+			err, _ := r.Context().Value(chaveErroAutenticacao).(error)
+			if err == nil {
+				err = autenticacao.ErrSemCredencial
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="concurso-go-app"`)
+			EscreverErro(sw, r, "", ErroNaoAutenticado(err.Error()))
+		case !principal.Pode(papel):
+			EscreverErro(sw, r, "", ErroProibido(fmt.Sprintf("papel %s não permite esta operação (exige %s)", principal.Papel, papel)))
+		default:
+			next(sw, r)
+		}
+
+		auditar(r, principal, autenticado, papel, sw.status, time.Since(inicio))
+	})
+}
+
+// auditar registra quem chamou o quê e com que resultado; recusas saem como Warn
+func auditar(r *http.Request, principal autenticacao.Principal, autenticado bool, exigido autenticacao.Papel, status int, duracao time.Duration) {
+	args := []any{
+		"metodo", r.Method,
+		"rota", rotaDe(r),
+		"papel_exigido", exigido.String(),
+		"status", status,
+		"duracao", duracao,
+	}
+	if autenticado {
+		// o nome do principal já vem dos atributos do contexto
+		args = append(args, "papel", principal.Papel.String(), "autenticacao", principal.Metodo)
+	} else {
+		args = append(args, logger.ChavePrincipal, "anonimo")
+	}
+
+	nivel := slog.LevelInfo
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		nivel = slog.LevelWarn
+	}
+	slog.Log(r.Context(), nivel, "auditoria", args...)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"concurso-go-app/internal/autenticacao"
+)
+
+func TestExigir(t *testing.T) {
+	t.Setenv("AUTH_ENABLED", "true")
+	t.Setenv("API_KEYS", "painel:leitura:chave-do-painel-0001,ci:operador:chave-de-ci-00000001,ops:admin:chave-de-ops-0000001")
+	t.Setenv("JWT_HS256_SECRET", "")
+	t.Setenv("JWT_JWKS_FILE", "")
+	aut, err := autenticacao.Carregar()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var chamado string
+	handler := Autenticacao(aut)(Exigir(autenticacao.PapelOperador, func(w http.ResponseWriter, r *http.Request) {
+		principal, _ := autenticacao.PrincipalDe(r.Context())
+		chamado = principal.Nome
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	casos := []struct {
+		nome    string
+		chave   string
+		status  int
+		codigo  string
+		chamado string
+	}{
+		{"sem credencial", "", http.StatusUnauthorized, CodigoNaoAutenticado, ""},
+		{"chave desconhecida", "chave-desconhecida-01", http.StatusUnauthorized, CodigoNaoAutenticado, ""},
+		{"papel insuficiente", "chave-do-painel-0001", http.StatusForbidden, CodigoProibido, ""},
+		{"papel exigido", "chave-de-ci-00000001", http.StatusAccepted, "", "ci"},
+		{"papel acima do exigido", "chave-de-ops-0000001", http.StatusAccepted, "", "ops"},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			chamado = ""
+			r := httptest.NewRequest(http.MethodPost, "/extrair/2024-01-01", nil)
+			if caso.chave != "" {
+				r.Header.Set(autenticacao.HeaderChaveAPI, caso.chave)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != caso.status {
+				t.Fatalf("status = %d, esperado %d (%s)", w.Code, caso.status, w.Body)
+			}
+			if caso.codigo != "" {
+				var resposta Erro
+				if err := json.Unmarshal(w.Body.Bytes(), &resposta); err != nil {
+					t.Fatal(err)
+				}
+				if resposta.Codigo != caso.codigo {
+					t.Fatalf("codigo = %s, esperado %s", resposta.Codigo, caso.codigo)
+				}
+			}
+			if caso.status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Fatal("401 sem WWW-Authenticate")
+			}
+			if chamado != caso.chamado {
+				t.Fatalf("handler chamado por %q, esperado %q", chamado, caso.chamado)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"concurso-go-app/internal/kafka"
+	"concurso-go-app/internal/services"
+)
+
+// Códigos de erro devolvidos no campo "codigo"
+const (
+	CodigoRequisicaoInvalida  = "requisicao_invalida"
+	CodigoNaoEncontrado       = "nao_encontrado"
+	CodigoConflito            = "conflito"
+	CodigoNaoAutenticado      = "nao_autenticado"
+	CodigoCorpoGrande         = "corpo_grande"
+	CodigoCancelado           = "cancelado"
+	CodigoTempoEsgotado       = "tempo_esgotado"
+	CodigoProibido            = "proibido"
+	CodigoServicoIndisponivel = "servico_indisponivel"
+	CodigoLoteInvalido        = "lote_invalido"
+	CodigoErroBanco           = "erro_banco"
+	CodigoErroKafka           = "erro_kafka"
+	CodigoErroInterno         = "erro_interno"
+)
+
+// StatusCancelado é devolvido quando a operação foi cancelada (cliente
+// desconectou ou DELETE /jobs/{id}), como o 499 do nginx
+const StatusCancelado = 499
+
+// Erro é o corpo de toda resposta de erro da API
+type Erro struct {
+	Status    int         `json:"-"`
+	Codigo    string      `json:"codigo"`
+	Mensagem  string      `json:"mensagem"`
+	Detalhes  interface{} `json:"detalhes,omitempty"`
+	RequestID string      `json:"request_id"`
+}
+
+func (e *Erro) Error() string {
+	return e.Mensagem
+}
+
+// ErroValidacao é um 400 com detalhes opcionais (ex.: o campo inválido)
+func ErroValidacao(mensagem string, detalhes interface{}) *Erro {
+	return &Erro{Status: http.StatusBadRequest, Codigo: CodigoRequisicaoInvalida, Mensagem: mensagem, Detalhes: detalhes}
+}
+
+// ErroNaoAutenticado é um 401 (credencial ausente ou inválida)
+func ErroNaoAutenticado(mensagem string) *Erro {
+	return &Erro{Status: http.StatusUnauthorized, Codigo: CodigoNaoAutenticado, Mensagem: mensagem}
+}
+
+// ErroCorpoGrande é um 413 (corpo acima do limite do endpoint)
+func ErroCorpoGrande(mensagem string) *Erro {
+	return &Erro{Status: http.StatusRequestEntityTooLarge, Codigo: CodigoCorpoGrande, Mensagem: mensagem}
+}
+
+// ErroProibido é um 403
+func ErroProibido(mensagem string) *Erro {
+	return &Erro{Status: http.StatusForbidden, Codigo: CodigoProibido, Mensagem: mensagem}
+}
+
+// ErroIndisponivel é um 503 (ex.: servidor em encerramento)
+func ErroIndisponivel(mensagem string) *Erro {
+	return &Erro{Status: http.StatusServiceUnavailable, Codigo: CodigoServicoIndisponivel, Mensagem: mensagem}
+}
+
+// ErroNaoEncontrado é um 404
+func ErroNaoEncontrado(mensagem string) *Erro {
+	return &Erro{Status: http.StatusNotFound, Codigo: CodigoNaoEncontrado, Mensagem: mensagem}
+}
+
+// ErroConflito é um 409 (ex.: cancelar um job já finalizado)
+func ErroConflito(mensagem string, detalhes interface{}) *Erro {
+	return &Erro{Status: http.StatusConflict, Codigo: CodigoConflito, Mensagem: mensagem, Detalhes: detalhes}
+}
+
+// EscreverErro responde com o erro classificado: *Erro é usado como está;
+// erros do serviço viram 404/422/499/503/500 conforme o tipo. contexto prefixa a
+// mensagem (ex.: "Erro ao extrair registros").
+func EscreverErro(w http.ResponseWriter, r *http.Request, contexto string, err error) {
+	resposta := classificar(err)
+	if contexto != "" && resposta.Codigo != CodigoRequisicaoInvalida {
+		resposta.Mensagem = contexto + ": " + resposta.Mensagem
+	}
+	resposta.RequestID = RequestIDDe(r.Context())
+
+	if resposta.Status >= http.StatusInternalServerError {
+		slog.ErrorContext(r.Context(), resposta.Mensagem, "status", resposta.Status, "codigo", resposta.Codigo)
+	}
+	EscreverJSON(w, resposta.Status, resposta)
+}
+
+func classificar(err error) Erro {
+	var erroAPI *Erro
+	if errors.As(err, &erroAPI) {
+		return *erroAPI
+	}
+
+	resposta := Erro{Status: http.StatusInternalServerError, Codigo: CodigoErroInterno, Mensagem: err.Error()}
+	var erroOperacao *services.ErroOperacao
+	if errors.As(err, &erroOperacao) {
+		resposta.Detalhes = map[string]string{"categoria": services.CategoriaErro(err), "operacao": erroOperacao.Operacao}
+	}
+	var erroLote *services.ErroLote
+	if errors.As(err, &erroLote) {
+		resposta.Detalhes = map[string]string{"categoria": services.CategoriaLote, "tipo": erroLote.Tipo.Error(), "data": erroLote.Data, "lote": erroLote.Lote}
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		resposta.Status, resposta.Codigo = StatusCancelado, CodigoCancelado
+	case errors.Is(err, context.DeadlineExceeded):
+		resposta.Status, resposta.Codigo = http.StatusGatewayTimeout, CodigoTempoEsgotado
+	case errors.Is(err, services.ErrSemRegistros):
+		resposta.Status, resposta.Codigo = http.StatusNotFound, CodigoNaoEncontrado
+	case errors.Is(err, services.ErrBancoIndisponivel), errors.Is(err, driver.ErrBadConn):
+		resposta.Status, resposta.Codigo = http.StatusServiceUnavailable, CodigoServicoIndisponivel
+	case errors.Is(err, services.ErrKafka):
+		resposta.Status, resposta.Codigo = statusKafka(err)
+	case erroLote != nil:
+		resposta.Status, resposta.Codigo = http.StatusUnprocessableEntity, CodigoLoteInvalido
+	case errors.Is(err, services.ErrBanco):
+		resposta.Codigo = CodigoErroBanco
+	}
+	return resposta
+}
+
+// statusKafka devolve 503 só quando o broker está indisponível (vale tentar
+// de novo), 404 para tópico inexistente e 500 para o resto: configuração,
+// esquema, serialização
+func statusKafka(err error) (int, string) {
+	switch {
+	case kafka.BrokerIndisponivel(err):
+		return http.StatusServiceUnavailable, CodigoServicoIndisponivel
+	case kafka.TopicoInexistente(err):
+		return http.StatusNotFound, CodigoNaoEncontrado
+	}
+	return http.StatusInternalServerError, CodigoErroKafka
+}
+
+// EscreverJSON serializa v antes de escrever, para nunca mandar JSON pela metade
+func EscreverJSON(w http.ResponseWriter, status int, v interface{}) {
+	corpo, err := json.Marshal(v)
+	if err != nil {
+		slog.Error("erro ao serializar resposta", "erro", err)
+		status = http.StatusInternalServerError
+		corpo = []byte(`{"codigo":"erro_interno","mensagem":"erro ao serializar resposta"}`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(corpo, '\n'))
+}
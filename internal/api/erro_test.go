@@ -0,0 +1,43 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"concurso-go-app/internal/kafka"
+	"concurso-go-app/internal/services"
+
+	"github.com/Shopify/sarama"
+)
+
+func TestClassificarErrosKafka(t *testing.T) {
+	erroKafka := func(causa error) error {
+		return &services.ErroOperacao{Categoria: services.ErrKafka, Operacao: "teste", Mensagem: "erro no Kafka", Causa: causa}
+	}
+	casos := []struct {
+		nome   string
+		err    error
+		status int
+		codigo string
+	}{
+		{"sem brokers", erroKafka(sarama.ErrOutOfBrokers), http.StatusServiceUnavailable, CodigoServicoIndisponivel},
+		{"tópico inexistente", erroKafka(sarama.ErrUnknownTopicOrPartition), http.StatusNotFound, CodigoNaoEncontrado},
+		{"mensagem grande", erroKafka(sarama.ErrMessageSizeTooLarge), http.StatusInternalServerError, CodigoErroKafka},
+		{"serialização", erroKafka(errors.New("campo obrigatório ausente")), http.StatusInternalServerError, CodigoErroKafka},
+		{"configuração", erroKafka(fmt.Errorf("%w: KAFKA_ACKS inválido", kafka.ErrConfiguracao)), http.StatusInternalServerError, CodigoErroKafka},
+		{"esquema", erroKafka(kafka.ErrEsquemaIncompativel), http.StatusInternalServerError, CodigoErroKafka},
+		{"prazo esgotado", erroKafka(context.DeadlineExceeded), http.StatusGatewayTimeout, CodigoTempoEsgotado},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			resposta := classificar(caso.err)
+			if resposta.Status != caso.status || resposta.Codigo != caso.codigo {
+				t.Errorf("classificar = %d %s, esperado %d %s", resposta.Status, resposta.Codigo, caso.status, caso.codigo)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"concurso-go-app/internal/rastreamento"
+)
+
+// Rastreamento abre um span por requisição, nomeado pela rota ("POST
+// /extrair/{data}"), continuando o trace do header traceparent recebido.
+// Deve vir depois de RequestID.
+func Rastreamento(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rota := rotaDe(r)
+
+		// O propagador procura "traceparent" em minúsculas; http.Header canoniza os nomes
+		headers := make(map[string]string, len(r.Header))
+		for nome := range r.Header {
+			headers[strings.ToLower(nome)] = r.Header.Get(nome)
+		}
+		ctx := rastreamento.Extrair(r.Context(), headers)
+		ctx, span := rastreamento.Span(ctx, r.Method+" "+rota,
+			attribute.String("http.request.method", r.Method),
+			attribute.String("http.route", rota),
+			attribute.String("url.path", r.URL.Path),
+			attribute.String("request_id", RequestIDDe(r.Context())),
+		)
+		defer span.End()
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r.WithContext(ctx))
+
+		span.SetAttributes(attribute.Int("http.response.status_code", sw.status))
+		if sw.status >= 500 {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", sw.status))
+		}
+	})
+}
+
+// rotaDe retorna o modelo da rota ("/extrair/{data}"), ou o caminho se não houver
+func rotaDe(r *http.Request) string {
+	if atual := mux.CurrentRoute(r); atual != nil {
+		if modelo, err := atual.GetPathTemplate(); err == nil {
+			return modelo
+		}
+	}
+	return r.URL.Path
+}
+
+// SpanDe retorna o span da requisição (no-op fora de Rastreamento)
+func SpanDe(r *http.Request) trace.Span {
+	return trace.SpanFromContext(r.Context())
+}
+
+// statusWriter guarda o status escrito pelo handler
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Flush repassa para o ResponseWriter original (respostas em streaming)
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap permite ao http.ResponseController chegar ao ResponseWriter original
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+
+	"concurso-go-app/internal/logger"
+)
+
+// HeaderRequestID identifica a requisição nos logs e nas respostas de erro
+const HeaderRequestID = "X-Request-ID"
+
+type chaveContexto int
+
+const (
+	chaveRequestID chaveContexto = iota
+	chaveErroAutenticacao
+)
+
+// RequestID reaproveita o X-Request-ID recebido (até 64 caracteres) ou gera
+// um novo, devolve no header da resposta e guarda no contexto (inclusive
+// como atributo das linhas de log da requisição)
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(HeaderRequestID)
+		if id == "" || len(id) > 64 {
+			b := make([]byte, 8)
+			cryptorand.Read(b)
+			id = hex.EncodeToString(b)
+		}
+		w.Header().Set(HeaderRequestID, id)
+		ctx := context.WithValue(r.Context(), chaveRequestID, id)
+		ctx = logger.ComAtributos(ctx, slog.String(logger.ChaveRequestID, id))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDDe retorna o request ID do contexto ("" fora de uma requisição)
+func RequestIDDe(ctx context.Context) string {
+	id, _ := ctx.Value(chaveRequestID).(string)
+	return id
+}
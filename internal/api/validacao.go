@@ -0,0 +1,73 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"concurso-go-app/internal/models"
+)
+
+// Data valida uma data YYYY-MM-DD de verdade (2025-13-99 é rejeitada)
+func Data(campo string, valor string) (time.Time, error) {
+	t, err := time.Parse(models.FormatoData, valor)
+	if err != nil {
+		return time.Time{}, ErroValidacao(fmt.Sprintf("%s inválida. Use YYYY-MM-DD", campo), map[string]string{"campo": campo, "valor": valor})
+	}
+	return t, nil
+}
+
+// DataOpcional valida a data só se ela foi informada
+func DataOpcional(campo string, valor string) error {
+	if valor == "" {
+		return nil
+	}
+	_, err := Data(campo, valor)
+	return err
+}
+
+// Intervalo valida de/ate obrigatórios, com ate >= de e no máximo maxDias
+// (0 = sem limite)
+func Intervalo(de string, ate string, maxDias int) error {
+	inicio, err := Data("de", de)
+	if err != nil {
+		return err
+	}
+	fim, err := Data("ate", ate)
+	if err != nil {
+		return err
+	}
+	if fim.Before(inicio) {
+		return ErroValidacao("ate deve ser igual ou posterior a de", map[string]string{"de": de, "ate": ate})
+	}
+	if maxDias > 0 && fim.Sub(inicio) > time.Duration(maxDias)*24*time.Hour {
+		return ErroValidacao(fmt.Sprintf("intervalo de no máximo %d dias", maxDias), map[string]string{"de": de, "ate": ate})
+	}
+	return nil
+}
+
+// Inteiro lê um inteiro opcional entre min e max; vazio devolve padrao
+func Inteiro(campo string, valor string, padrao int, min int, max int) (int, error) {
+	if valor == "" {
+		return padrao, nil
+	}
+	n, err := strconv.Atoi(valor)
+	if err != nil || n < min || n > max {
+		return 0, ErroValidacao(fmt.Sprintf("%s deve ser entre %d e %d", campo, min, max), map[string]string{"campo": campo, "valor": valor})
+	}
+	return n, nil
+}
+
+// Opcao confere se o valor está entre as opções; vazio devolve padrao
+func Opcao(campo string, valor string, padrao string, opcoes ...string) (string, error) {
+	if valor == "" {
+		return padrao, nil
+	}
+	for _, opcao := range opcoes {
+		if valor == opcao {
+			return valor, nil
+		}
+	}
+	return "", ErroValidacao(fmt.Sprintf("%s inválido: use %s", campo, strings.Join(opcoes, ", ")), map[string]interface{}{"campo": campo, "valor": valor, "opcoes": opcoes})
+}
@@ -0,0 +1,294 @@
+package autenticacao
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Papel define o que o principal pode fazer; cada papel inclui os anteriores
+type Papel int
+
+const (
+	// PapelLeitura consulta tabelas, relatórios e jobs
+	PapelLeitura Papel = iota + 1
+	// PapelOperador também extrai, consome e cancela jobs
+	PapelOperador
+	// PapelAdmin também popula a tabela concurso (/start) e limpa o Kafka (/limpar)
+	PapelAdmin
+)
+
+var nomesPapeis = map[Papel]string{
+	PapelLeitura:  "leitura",
+	PapelOperador: "operador",
+	PapelAdmin:    "admin",
+}
+
+func (p Papel) String() string {
+	if nome, ok := nomesPapeis[p]; ok {
+		return nome
+	}
+	return "desconhecido"
+}
+
+// PapelDe converte o nome ("leitura", "operador" ou "admin") no papel
+func PapelDe(nome string) (Papel, bool) {
+	for papel, n := range nomesPapeis {
+		if strings.EqualFold(nome, n) {
+			return papel, true
+		}
+	}
+	return 0, false
+}
+
+// Métodos de autenticação registrados na auditoria
+const (
+	MetodoChaveAPI     = "api_key"
+	MetodoJWT          = "jwt"
+	MetodoDesabilitado = "desabilitado"
+)
+
+// HeaderChaveAPI carrega a chave estática; JWT vai em Authorization: Bearer
+const HeaderChaveAPI = "X-API-Key"
+
+var (
+	// ErrSemCredencial indica requisição sem X-API-Key nem Authorization
+	ErrSemCredencial = errors.New("credencial ausente: use o header X-API-Key ou Authorization: Bearer <token>")
+	// ErrCredencialInvalida indica chave desconhecida ou token rejeitado
+	ErrCredencialInvalida = errors.New("credencial inválida")
+)
+
+// Principal é quem fez a chamada
+type Principal struct {
+	Nome   string `json:"nome"`
+	Papel  Papel  `json:"-"`
+	Metodo string `json:"metodo"`
+}
+
+// Pode informa se o papel do principal cobre o exigido
+func (p Principal) Pode(exigido Papel) bool {
+	return p.Papel >= exigido
+}
+
+type chaveAPI struct {
+	nome  string
+	papel Papel
+	chave []byte
+}
+
+// Autenticador valida chaves de API estáticas e JWT (HS256 com segredo
+// compartilhado, RS256 com as chaves de um arquivo JWKS local)
+type Autenticador struct {
+	habilitado   bool
+	chaves       []chaveAPI
+	segredoHS256 []byte
+	jwks         *conjuntoChaves
+	metodos      []string
+	opcoes       []jwt.ParserOption
+	claimPapel   string
+}
+
+// Carregar lê a configuração:
+//   - AUTH_ENABLED (padrão true); false libera tudo como admin, só para desenvolvimento
+//   - API_KEYS: lista "nome:papel:chave" separada por vírgulas
+//   - JWT_HS256_SECRET: segredo dos tokens HS256
+//   - JWT_JWKS_FILE: arquivo JWKS com as chaves públicas RSA dos tokens RS256
+//   - JWT_ISSUER e JWT_AUDIENCE: se definidos, exigidos em iss e aud
+//   - JWT_ROLE_CLAIM: claim com o papel (padrão "role"; string ou lista)
+//
+// Com autenticação habilitada, ao menos uma credencial precisa estar configurada.
+func Carregar() (*Autenticador, error) {
+	habilitado := true
+	if valor := os.Getenv("AUTH_ENABLED"); valor != "" {
+		b, err := strconv.ParseBool(valor)
+		if err != nil {
+			return nil, fmt.Errorf("AUTH_ENABLED inválido: %s", valor)
+		}
+		habilitado = b
+	}
+	a := &Autenticador{habilitado: habilitado, claimPapel: os.Getenv("JWT_ROLE_CLAIM")}
+	if !habilitado {
+		return a, nil
+	}
+	if a.claimPapel == "" {
+		a.claimPapel = "role"
+	}
+
+	chaves, err := lerChavesAPI(os.Getenv("API_KEYS"))
+	if err != nil {
+		return nil, err
+	}
+	a.chaves = chaves
+
+	if segredo := os.Getenv("JWT_HS256_SECRET"); segredo != "" {
+		if len(segredo) < 32 {
+			return nil, fmt.Errorf("JWT_HS256_SECRET precisa de ao menos 32 caracteres")
+		}
+		a.segredoHS256 = []byte(segredo)
+		a.metodos = append(a.metodos, jwt.SigningMethodHS256.Alg())
+	}
+	if caminho := os.Getenv("JWT_JWKS_FILE"); caminho != "" {
+		jwks, err := lerJWKS(caminho)
+		if err != nil {
+			return nil, err
+		}
+		a.jwks = jwks
+		a.metodos = append(a.metodos, jwt.SigningMethodRS256.Alg())
+	}
+
+	if len(a.chaves) == 0 && len(a.metodos) == 0 {
+		return nil, fmt.Errorf("autenticação habilitada sem credenciais: defina API_KEYS, JWT_HS256_SECRET ou JWT_JWKS_FILE (ou AUTH_ENABLED=false)")
+	}
+
+	a.opcoes = []jwt.ParserOption{
+		jwt.WithValidMethods(a.metodos),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if emissor := os.Getenv("JWT_ISSUER"); emissor != "" {
+		a.opcoes = append(a.opcoes, jwt.WithIssuer(emissor))
+	}
+	if audiencia := os.Getenv("JWT_AUDIENCE"); audiencia != "" {
+		a.opcoes = append(a.opcoes, jwt.WithAudience(audiencia))
+	}
+	return a, nil
+}
+
+// Habilitado informa se as credenciais são verificadas
+func (a *Autenticador) Habilitado() bool {
+	return a.habilitado
+}
+
+// Autenticar identifica o principal pela X-API-Key ou pelo JWT em
+// Authorization: Bearer. Retorna ErrSemCredencial se não houver nenhum dos dois.
+func (a *Autenticador) Autenticar(r *http.Request) (Principal, error) {
+	if !a.habilitado {
+		return Principal{Nome: "anonimo", Papel: PapelAdmin, Metodo: MetodoDesabilitado}, nil
+	}
+
+	if chave := r.Header.Get(HeaderChaveAPI); chave != "" {
+		return a.autenticarChave(chave)
+	}
+
+	autorizacao := r.Header.Get("Authorization")
+	if autorizacao == "" {
+		return Principal{}, ErrSemCredencial
+	}
+	esquema, token, ok := strings.Cut(autorizacao, " ")
+	if !ok || !strings.EqualFold(esquema, "Bearer") || token == "" {
+		return Principal{}, fmt.Errorf("%w: Authorization deve ser \"Bearer <token>\"", ErrCredencialInvalida)
+	}
+	return a.autenticarJWT(strings.TrimSpace(token))
+}
+
+func (a *Autenticador) autenticarChave(chave string) (Principal, error) {
+	// Compara com todas as chaves para o tempo não revelar qual existe
+	var encontrada *chaveAPI
+	for i := range a.chaves {
+		if subtle.ConstantTimeCompare([]byte(chave), a.chaves[i].chave) == 1 {
+			encontrada = &a.chaves[i]
+		}
+	}
+	if encontrada == nil {
+		return Principal{}, fmt.Errorf("%w: chave de API desconhecida", ErrCredencialInvalida)
+	}
+	return Principal{Nome: encontrada.nome, Papel: encontrada.papel, Metodo: MetodoChaveAPI}, nil
+}
+
+func (a *Autenticador) autenticarJWT(token string) (Principal, error) {
+	if len(a.metodos) == 0 {
+		return Principal{}, fmt.Errorf("%w: JWT não configurado", ErrCredencialInvalida)
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, a.chaveDoToken, a.opcoes...); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrCredencialInvalida, err)
+	}
+
+	sujeito, err := claims.GetSubject()
+	if err != nil || sujeito == "" {
+		return Principal{}, fmt.Errorf("%w: token sem sub", ErrCredencialInvalida)
+	}
+	papel, ok := papelDasClaims(claims[a.claimPapel])
+	if !ok {
+		return Principal{}, fmt.Errorf("%w: token sem papel válido na claim %s", ErrCredencialInvalida, a.claimPapel)
+	}
+	return Principal{Nome: sujeito, Papel: papel, Metodo: MetodoJWT}, nil
+}
+
+// chaveDoToken escolhe a chave de verificação pelo algoritmo (e pelo kid no RS256)
+func (a *Autenticador) chaveDoToken(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return a.segredoHS256, nil
+	case *jwt.SigningMethodRSA:
+		kid, _ := token.Header["kid"].(string)
+		return a.jwks.chave(kid)
+	}
+	return nil, fmt.Errorf("algoritmo não suportado: %v", token.Header["alg"])
+}
+
+// papelDasClaims aceita o papel como string ou lista; numa lista vale o maior
+func papelDasClaims(valor interface{}) (Papel, bool) {
+	switch v := valor.(type) {
+	case string:
+		return PapelDe(v)
+	case []interface{}:
+		var maior Papel
+		for _, item := range v {
+			if nome, ok := item.(string); ok {
+				if papel, ok := PapelDe(nome); ok && papel > maior {
+					maior = papel
+				}
+			}
+		}
+		return maior, maior != 0
+	}
+	return 0, false
+}
+
+func lerChavesAPI(valor string) ([]chaveAPI, error) {
+	var chaves []chaveAPI
+	for _, item := range strings.Split(valor, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		partes := strings.SplitN(item, ":", 3)
+		if len(partes) != 3 || partes[0] == "" || partes[2] == "" {
+			return nil, fmt.Errorf("API_KEYS inválido: use nome:papel:chave separados por vírgula")
+		}
+		papel, ok := PapelDe(partes[1])
+		if !ok {
+			return nil, fmt.Errorf("API_KEYS: papel inválido para %s: %s (use leitura, operador ou admin)", partes[0], partes[1])
+		}
+		if len(partes[2]) < 16 {
+			return nil, fmt.Errorf("API_KEYS: a chave de %s precisa de ao menos 16 caracteres", partes[0])
+		}
+		chaves = append(chaves, chaveAPI{nome: partes[0], papel: papel, chave: []byte(partes[2])})
+	}
+	return chaves, nil
+}
+
+type chaveContexto int
+
+const chavePrincipal chaveContexto = iota
+
+// ComPrincipal guarda o principal no contexto
+func ComPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, chavePrincipal, principal)
+}
+
+// PrincipalDe retorna o principal da requisição (false fora de uma chamada autenticada)
+func PrincipalDe(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(chavePrincipal).(Principal)
+	return principal, ok
+}
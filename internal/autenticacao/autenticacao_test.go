@@ -0,0 +1,299 @@
+package autenticacao
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const segredoTeste = "segredo-de-teste-com-mais-de-32-caracteres"
+
+// configurar define as variáveis de autenticação do teste, sem herdar as do ambiente
+func configurar(t *testing.T, variaveis map[string]string) {
+	t.Helper()
+	for _, nome := range []string{"AUTH_ENABLED", "API_KEYS", "JWT_HS256_SECRET", "JWT_JWKS_FILE", "JWT_ISSUER", "JWT_AUDIENCE", "JWT_ROLE_CLAIM"} {
+		t.Setenv(nome, variaveis[nome])
+	}
+}
+
+func carregar(t *testing.T, variaveis map[string]string) *Autenticador {
+	t.Helper()
+	configurar(t, variaveis)
+	a, err := Carregar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// requisicao monta um GET com os headers informados
+func requisicao(headers map[string]string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/concursos", nil)
+	for nome, valor := range headers {
+		r.Header.Set(nome, valor)
+	}
+	return r
+}
+
+func TestCarregarChavesAPIInvalidas(t *testing.T) {
+	casos := []struct {
+		nome   string
+		chaves string
+		erro   string
+	}{
+		{"sem papel", "ci:chave-de-ci-com-16-caracteres", "use nome:papel:chave"},
+		{"sem nome", ":leitura:chave-de-ci-com-16-caracteres", "use nome:papel:chave"},
+		{"sem chave", "ci:leitura:", "use nome:papel:chave"},
+		{"papel desconhecido", "ci:dono:chave-de-ci-com-16-caracteres", "papel inválido para ci: dono"},
+		{"chave curta", "ci:leitura:curta", "a chave de ci precisa de ao menos 16"},
+		{"segunda inválida", "ci:leitura:chave-de-ci-com-16-caracteres,painel", "use nome:papel:chave"},
+		{"vazia", " , ", "autenticação habilitada sem credenciais"},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			configurar(t, map[string]string{"API_KEYS": caso.chaves})
+			_, err := Carregar()
+			if err == nil || !strings.Contains(err.Error(), caso.erro) {
+				t.Fatalf("erro = %v, esperado contendo %q", err, caso.erro)
+			}
+		})
+	}
+}
+
+func TestAutenticarChaveAPI(t *testing.T) {
+	a := carregar(t, map[string]string{"API_KEYS": "painel:leitura:chave-do-painel-0001, ci:OPERADOR:chave-de-ci-00000001"})
+
+	casos := []struct {
+		nome     string
+		headers  map[string]string
+		esperado Principal
+		erro     error
+	}{
+		{"leitura", map[string]string{HeaderChaveAPI: "chave-do-painel-0001"}, Principal{Nome: "painel", Papel: PapelLeitura, Metodo: MetodoChaveAPI}, nil},
+		{"papel em maiúsculas", map[string]string{HeaderChaveAPI: "chave-de-ci-00000001"}, Principal{Nome: "ci", Papel: PapelOperador, Metodo: MetodoChaveAPI}, nil},
+		{"chave desconhecida", map[string]string{HeaderChaveAPI: "chave-do-painel-0002"}, Principal{}, ErrCredencialInvalida},
+		{"prefixo de chave válida", map[string]string{HeaderChaveAPI: "chave-do-painel"}, Principal{}, ErrCredencialInvalida},
+		{"sem credencial", nil, Principal{}, ErrSemCredencial},
+		{"authorization sem bearer", map[string]string{"Authorization": "Basic Y2k6c2VuaGE="}, Principal{}, ErrCredencialInvalida},
+		{"jwt não configurado", map[string]string{"Authorization": "Bearer abc.def.ghi"}, Principal{}, ErrCredencialInvalida},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			principal, err := a.Autenticar(requisicao(caso.headers))
+			if !errors.Is(err, caso.erro) {
+				t.Fatalf("erro = %v, esperado %v", err, caso.erro)
+			}
+			if principal != caso.esperado {
+				t.Fatalf("principal = %+v, esperado %+v", principal, caso.esperado)
+			}
+		})
+	}
+}
+
+func TestAutenticacaoDesabilitada(t *testing.T) {
+	a := carregar(t, map[string]string{"AUTH_ENABLED": "false"})
+	principal, err := a.Autenticar(requisicao(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.Papel != PapelAdmin || principal.Metodo != MetodoDesabilitado {
+		t.Fatalf("principal = %+v, esperado admin desabilitado", principal)
+	}
+}
+
+func TestPapelDe(t *testing.T) {
+	casos := []struct {
+		nome     string
+		esperado Papel
+		ok       bool
+	}{
+		{"leitura", PapelLeitura, true},
+		{"operador", PapelOperador, true},
+		{"Admin", PapelAdmin, true},
+		{"dono", 0, false},
+		{"", 0, false},
+	}
+
+	for _, caso := range casos {
+		papel, ok := PapelDe(caso.nome)
+		if papel != caso.esperado || ok != caso.ok {
+			t.Errorf("PapelDe(%q) = %v, %v; esperado %v, %v", caso.nome, papel, ok, caso.esperado, caso.ok)
+		}
+	}
+}
+
+func TestPapelDasClaims(t *testing.T) {
+	casos := []struct {
+		nome     string
+		valor    interface{}
+		esperado Papel
+		ok       bool
+	}{
+		{"string", "operador", PapelOperador, true},
+		{"string desconhecida", "dono", 0, false},
+		{"lista vale o maior", []interface{}{"leitura", "admin", "operador"}, PapelAdmin, true},
+		{"lista ignora desconhecidos", []interface{}{"dono", 42, "leitura"}, PapelLeitura, true},
+		{"lista sem papel válido", []interface{}{"dono", 42}, 0, false},
+		{"lista vazia", []interface{}{}, 0, false},
+		{"ausente", nil, 0, false},
+		{"número", 3.0, 0, false},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			papel, ok := papelDasClaims(caso.valor)
+			if papel != caso.esperado || ok != caso.ok {
+				t.Fatalf("papelDasClaims = %v, %v; esperado %v, %v", papel, ok, caso.esperado, caso.ok)
+			}
+		})
+	}
+}
+
+// gravarJWKS grava a chave pública num JWKS com o kid informado
+func gravarJWKS(t *testing.T, kid string, publica *rsa.PublicKey) string {
+	t.Helper()
+	conteudo, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(publica.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publica.E)).Bytes()),
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caminho := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(caminho, conteudo, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return caminho
+}
+
+func assinar(t *testing.T, metodo jwt.SigningMethod, kid string, chave interface{}, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(metodo, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	assinado, err := token.SignedString(chave)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return assinado
+}
+
+func TestAutenticarJWT(t *testing.T) {
+	privada, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outra, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := carregar(t, map[string]string{
+		"JWT_HS256_SECRET": segredoTeste,
+		"JWT_JWKS_FILE":    gravarJWKS(t, "k1", &privada.PublicKey),
+		"JWT_ISSUER":       "https://sso.exemplo",
+		"JWT_AUDIENCE":     "concurso-go-app",
+	})
+
+	agora := time.Now()
+	claims := func(alterar func(jwt.MapClaims)) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"sub":  "maria",
+			"role": "operador",
+			"iss":  "https://sso.exemplo",
+			"aud":  "concurso-go-app",
+			"exp":  agora.Add(time.Hour).Unix(),
+		}
+		if alterar != nil {
+			alterar(c)
+		}
+		return c
+	}
+	hs256 := []byte(segredoTeste)
+
+	casos := []struct {
+		nome  string
+		token string
+		papel Papel
+		erro  string
+	}{
+		{"hs256 válido", assinar(t, jwt.SigningMethodHS256, "", hs256, claims(nil)), PapelOperador, ""},
+		{"rs256 válido", assinar(t, jwt.SigningMethodRS256, "k1", privada, claims(nil)), PapelOperador, ""},
+		{"rs256 sem kid com uma chave", assinar(t, jwt.SigningMethodRS256, "", privada, claims(nil)), PapelOperador, ""},
+		{"papel em lista", assinar(t, jwt.SigningMethodHS256, "", hs256, claims(func(c jwt.MapClaims) { c["role"] = []string{"leitura", "admin"} })), PapelAdmin, ""},
+		{"expirado", assinar(t, jwt.SigningMethodHS256, "", hs256, claims(func(c jwt.MapClaims) { c["exp"] = agora.Add(-time.Hour).Unix() })), 0, "expired"},
+		{"sem exp", assinar(t, jwt.SigningMethodHS256, "", hs256, claims(func(c jwt.MapClaims) { delete(c, "exp") })), 0, "exp claim is required"},
+		{"segredo errado", assinar(t, jwt.SigningMethodHS256, "", []byte(segredoTeste+"x"), claims(nil)), 0, "signature is invalid"},
+		{"kid desconhecido", assinar(t, jwt.SigningMethodRS256, "k2", privada, claims(nil)), 0, "kid desconhecido"},
+		{"chave fora do jwks", assinar(t, jwt.SigningMethodRS256, "k1", outra, claims(nil)), 0, "verification error"},
+		{"algoritmo fora da lista", assinar(t, jwt.SigningMethodHS384, "", hs256, claims(nil)), 0, "signing method HS384 is invalid"},
+		{"emissor errado", assinar(t, jwt.SigningMethodHS256, "", hs256, claims(func(c jwt.MapClaims) { c["iss"] = "https://outro.exemplo" })), 0, "token has invalid issuer"},
+		{"audiência errada", assinar(t, jwt.SigningMethodHS256, "", hs256, claims(func(c jwt.MapClaims) { c["aud"] = "outro-app" })), 0, "token has invalid audience"},
+		{"sem sub", assinar(t, jwt.SigningMethodHS256, "", hs256, claims(func(c jwt.MapClaims) { delete(c, "sub") })), 0, "token sem sub"},
+		{"sem papel", assinar(t, jwt.SigningMethodHS256, "", hs256, claims(func(c jwt.MapClaims) { delete(c, "role") })), 0, "sem papel válido"},
+		{"alg none", assinar(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, claims(nil)), 0, "signing method none is invalid"},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			principal, err := a.Autenticar(requisicao(map[string]string{"Authorization": "Bearer " + caso.token}))
+			if caso.erro != "" {
+				if !errors.Is(err, ErrCredencialInvalida) || !strings.Contains(err.Error(), caso.erro) {
+					t.Fatalf("erro = %v, esperado credencial inválida com %q", err, caso.erro)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			esperado := Principal{Nome: "maria", Papel: caso.papel, Metodo: MetodoJWT}
+			if principal != esperado {
+				t.Fatalf("principal = %+v, esperado %+v", principal, esperado)
+			}
+		})
+	}
+}
+
+// Com só RS256 configurado, um token HS256 assinado com a chave pública do
+// JWKS (confusão de algoritmo) não pode ser aceito
+func TestAutenticarJWTConfusaoAlgoritmo(t *testing.T) {
+	privada, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := carregar(t, map[string]string{"JWT_JWKS_FILE": gravarJWKS(t, "k1", &privada.PublicKey)})
+
+	der, err := x509.MarshalPKIXPublicKey(&privada.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicaPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	claims := jwt.MapClaims{"sub": "intruso", "role": "admin", "exp": time.Now().Add(time.Hour).Unix()}
+
+	for _, segredo := range [][]byte{publicaPEM, der, privada.PublicKey.N.Bytes()} {
+		token := assinar(t, jwt.SigningMethodHS256, "k1", segredo, claims)
+		_, err := a.Autenticar(requisicao(map[string]string{"Authorization": "Bearer " + token}))
+		if !errors.Is(err, ErrCredencialInvalida) || !strings.Contains(err.Error(), "signing method HS256 is invalid") {
+			t.Fatalf("erro = %v, esperado HS256 recusado", err)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package autenticacao
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// conjuntoChaves são as chaves públicas RSA de um JWKS, por kid
+type conjuntoChaves struct {
+	chaves map[string]*rsa.PublicKey
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// lerJWKS carrega as chaves RSA de assinatura do arquivo; outras são ignoradas
+func lerJWKS(caminho string) (*conjuntoChaves, error) {
+	conteudo, err := os.ReadFile(caminho)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler JWT_JWKS_FILE: %v", err)
+	}
+	var arquivo struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(conteudo, &arquivo); err != nil {
+		return nil, fmt.Errorf("JWT_JWKS_FILE inválido: %v", err)
+	}
+
+	conjunto := &conjuntoChaves{chaves: make(map[string]*rsa.PublicKey)}
+	for _, chave := range arquivo.Keys {
+		if chave.Kty != "RSA" || (chave.Use != "" && chave.Use != "sig") {
+			continue
+		}
+		publica, err := chave.rsa()
+		if err != nil {
+			return nil, fmt.Errorf("JWT_JWKS_FILE: chave %q inválida: %v", chave.Kid, err)
+		}
+		conjunto.chaves[chave.Kid] = publica
+	}
+	if len(conjunto.chaves) == 0 {
+		return nil, fmt.Errorf("JWT_JWKS_FILE sem chaves RSA de assinatura")
+	}
+	return conjunto, nil
+}
+
+func (k jwk) rsa() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("n: %v", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("e: %v", err)
+	}
+	expoente := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !expoente.IsInt64() || expoente.Int64() < 3 || expoente.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("módulo ou expoente inválido")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(expoente.Int64())}, nil
+}
+
+// chave retorna a chave do kid; sem kid, só se o JWKS tiver uma única chave
+func (c *conjuntoChaves) chave(kid string) (*rsa.PublicKey, error) {
+	if c == nil {
+		return nil, fmt.Errorf("JWKS não configurado")
+	}
+	if chave, ok := c.chaves[kid]; ok {
+		return chave, nil
+	}
+	if kid == "" && len(c.chaves) == 1 {
+		for _, chave := range c.chaves {
+			return chave, nil
+		}
+	}
+	return nil, fmt.Errorf("kid desconhecido: %q", kid)
+}
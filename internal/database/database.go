@@ -0,0 +1,167 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bancos suportados em DB_DRIVER
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+var DB *sql.DB
+
+// Driver é o banco em uso, definido por DB_DRIVER (padrão mysql)
+var Driver string
+
+func InitDB() error {
+	Driver = strings.ToLower(os.Getenv("DB_DRIVER"))
+	if Driver == "" {
+		Driver = DriverMySQL
+	}
+
+	var nomeDriver, dsn string
+	var err error
+	switch Driver {
+	case DriverMySQL:
+		nomeDriver = "mysql"
+		dsn, err = dsnMySQL()
+	case DriverPostgres:
+		nomeDriver = "postgres"
+		dsn, err = dsnPostgres()
+	case DriverSQLite:
+		nomeDriver = "sqlite"
+		dsn, err = dsnSQLite()
+	default:
+		return fmt.Errorf("DB_DRIVER não suportado: %s (use mysql, postgres ou sqlite)", Driver)
+	}
+	if err != nil {
+		return fmt.Errorf("erro na configuração do banco: %v", err)
+	}
+
+	DB, err = sql.Open(nomeDriver, dsn)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir conexão: %v", err)
+	}
+
+	configurarPool(DB)
+
+	if err := aguardarBanco(DB); err != nil {
+		return fmt.Errorf("erro ao conectar ao banco: %v", err)
+	}
+
+	slog.Info("conectado ao banco", "driver", Driver)
+	return nil
+}
+
+// Rebind converte os placeholders "?" para o formato do banco em uso
+// ($1, $2... no PostgreSQL). As queries do projeto são escritas com "?".
+func Rebind(query string) string {
+	if Driver != DriverPostgres {
+		return query
+	}
+
+	var resultado strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			resultado.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		resultado.WriteRune(c)
+	}
+	return resultado.String()
+}
+
+// LimitarWorkers ajusta quantas goroutines podem usar o banco ao mesmo tempo,
+// deixando ao menos uma conexão do pool livre para o resto da aplicação (no
+// SQLite, com uma conexão só, fica um worker)
+func LimitarWorkers(db *sql.DB, workers int) int {
+	if maximo := db.Stats().MaxOpenConnections; maximo > 0 && workers >= maximo {
+		workers = maximo - 1
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
+
+// configurarPool limita as conexões para vários jobs concorrentes não esgotarem o MySQL
+func configurarPool(db *sql.DB) {
+	db.SetMaxOpenConns(envInt("DB_MAX_OPEN_CONNS", 20))
+	if Driver == DriverSQLite {
+		// SQLite aceita um escritor por vez: uma conexão evita "database is locked"
+		db.SetMaxOpenConns(1)
+	}
+	db.SetMaxIdleConns(envInt("DB_MAX_IDLE_CONNS", 10))
+
+	if duracao, err := envDuracao("DB_CONN_MAX_LIFETIME", 5*time.Minute); err == nil {
+		db.SetConnMaxLifetime(duracao)
+	} else {
+		slog.Warn("configuração do pool ignorada", "erro", err)
+	}
+	if duracao, err := envDuracao("DB_CONN_MAX_IDLE_TIME", time.Minute); err == nil {
+		db.SetConnMaxIdleTime(duracao)
+	} else {
+		slog.Warn("configuração do pool ignorada", "erro", err)
+	}
+}
+
+// aguardarBanco tenta o Ping com backoff exponencial até DB_STARTUP_TIMEOUT,
+// para a aplicação poder subir antes do MySQL ficar pronto
+func aguardarBanco(db *sql.DB) error {
+	limite, err := envDuracao("DB_STARTUP_TIMEOUT", time.Minute)
+	if err != nil {
+		return err
+	}
+
+	prazo := time.Now().Add(limite)
+	espera := 500 * time.Millisecond
+	for tentativa := 1; ; tentativa++ {
+		err := db.Ping()
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().Add(espera).After(prazo) {
+			return fmt.Errorf("banco indisponível após %d tentativas em %s: %v", tentativa, limite, err)
+		}
+
+		slog.Warn("banco indisponível, nova tentativa", "tentativa", tentativa, "erro", err, "espera", espera)
+		time.Sleep(espera)
+
+		espera *= 2
+		if espera > 10*time.Second {
+			espera = 10 * time.Second
+		}
+	}
+}
+
+func envInt(nome string, padrao int) int {
+	valor, err := strconv.Atoi(os.Getenv(nome))
+	if err != nil {
+		return padrao
+	}
+	return valor
+}
+
+func envDuracao(nome string, padrao time.Duration) (time.Duration, error) {
+	valor := os.Getenv(nome)
+	if valor == "" {
+		return padrao, nil
+	}
+	duracao, err := time.ParseDuration(valor)
+	if err != nil {
+		return 0, fmt.Errorf("%s inválido: %v", nome, err)
+	}
+	return duracao, nil
+}
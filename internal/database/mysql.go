@@ -1,35 +1,83 @@
 package database
 
 import (
-	"database/sql"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
-	"log"
+	"net"
 	"os"
+	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
-var DB *sql.DB
+// dsnMySQL monta o DSN a partir das variáveis DB_*: timezone, timeouts e TLS
+func dsnMySQL() (string, error) {
+	config := mysql.NewConfig()
+	config.User = os.Getenv("DB_USER")
+	config.Passwd = os.Getenv("DB_PASSWORD")
+	config.Net = "tcp"
+	config.Addr = net.JoinHostPort(os.Getenv("DB_HOST"), os.Getenv("DB_PORT"))
+	config.DBName = os.Getenv("DB_NAME")
+	config.ParseTime = true
 
-func InitDB() error {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_NAME"),
-	)
+	if timezone := os.Getenv("DB_TIMEZONE"); timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return "", fmt.Errorf("DB_TIMEZONE inválido: %v", err)
+		}
+		config.Loc = loc
+	}
 
 	var err error
-	DB, err = sql.Open("mysql", dsn)
+	if config.Timeout, err = envDuracao("DB_TIMEOUT", 10*time.Second); err != nil {
+		return "", err
+	}
+	if config.ReadTimeout, err = envDuracao("DB_READ_TIMEOUT", 0); err != nil {
+		return "", err
+	}
+	if config.WriteTimeout, err = envDuracao("DB_WRITE_TIMEOUT", 0); err != nil {
+		return "", err
+	}
+
+	if config.TLSConfig, err = configTLSMySQL(); err != nil {
+		return "", err
+	}
+
+	return config.FormatDSN(), nil
+}
+
+// configTLSMySQL interpreta DB_TLS: true, false, skip-verify, preferred ou custom
+// (custom usa DB_TLS_CA_FILE e, opcionalmente, DB_TLS_CERT_FILE/DB_TLS_KEY_FILE)
+func configTLSMySQL() (string, error) {
+	modo := os.Getenv("DB_TLS")
+	if modo != "custom" {
+		return modo, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	caFile := os.Getenv("DB_TLS_CA_FILE")
+	ca, err := os.ReadFile(caFile)
 	if err != nil {
-		return fmt.Errorf("erro ao abrir conexão: %v", err)
+		return "", fmt.Errorf("erro ao ler DB_TLS_CA_FILE: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return "", fmt.Errorf("nenhum certificado válido em %s", caFile)
 	}
+	tlsConfig.RootCAs = pool
 
-	if err := DB.Ping(); err != nil {
-		return fmt.Errorf("erro ao conectar ao banco: %v", err)
+	if certFile := os.Getenv("DB_TLS_CERT_FILE"); certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, os.Getenv("DB_TLS_KEY_FILE"))
+		if err != nil {
+			return "", fmt.Errorf("erro ao carregar certificado de cliente do banco: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
 	}
 
-	log.Println("Conectado ao MySQL com sucesso")
-	return nil
+	if err := mysql.RegisterTLSConfig("custom", tlsConfig); err != nil {
+		return "", err
+	}
+	return "custom", nil
 }
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// dsnPostgres monta a URL de conexão do PostgreSQL a partir das mesmas variáveis DB_*
+func dsnPostgres() (string, error) {
+	dsn := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD")),
+		Host:   net.JoinHostPort(os.Getenv("DB_HOST"), os.Getenv("DB_PORT")),
+		Path:   "/" + os.Getenv("DB_NAME"),
+	}
+
+	params := url.Values{}
+
+	// DB_TLS usa os mesmos valores do MySQL, convertidos para sslmode
+	switch os.Getenv("DB_TLS") {
+	case "", "false":
+		params.Set("sslmode", "disable")
+	case "true":
+		params.Set("sslmode", "verify-full")
+	case "skip-verify", "preferred":
+		params.Set("sslmode", "require")
+	case "custom":
+		params.Set("sslmode", "verify-full")
+		params.Set("sslrootcert", os.Getenv("DB_TLS_CA_FILE"))
+		if certFile := os.Getenv("DB_TLS_CERT_FILE"); certFile != "" {
+			params.Set("sslcert", certFile)
+			params.Set("sslkey", os.Getenv("DB_TLS_KEY_FILE"))
+		}
+	default:
+		return "", fmt.Errorf("DB_TLS inválido: %s", os.Getenv("DB_TLS"))
+	}
+
+	timeout, err := envDuracao("DB_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return "", err
+	}
+	params.Set("connect_timeout", strconv.Itoa(int(timeout.Seconds())))
+
+	if timezone := os.Getenv("DB_TIMEZONE"); timezone != "" {
+		params.Set("timezone", timezone)
+	}
+
+	dsn.RawQuery = params.Encode()
+	return dsn.String(), nil
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Códigos que indicam falha transitória: o servidor desfez o comando (ou a
+// transação), então repetir não aplica nada duas vezes
+const (
+	erroLockWaitTimeout = 1205 // MySQL
+	erroDeadlock        = 1213 // MySQL
+
+	pgSerializationFailure = "40001"
+	pgDeadlock             = "40P01"
+)
+
+// ErroTransitorio indica se vale a pena repetir a operação: só erros em que
+// nada foi aplicado, ou seja, driver.ErrBadConn (conexão descartada antes de
+// enviar o comando), deadlock e lock wait timeout. mysql.ErrInvalidConn fica
+// de fora: o driver também o devolve quando a conexão cai depois de o servidor
+// aplicar a escrita ou o COMMIT, e repetir duplicaria as linhas.
+func ErroTransitorio(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == erroDeadlock || mysqlErr.Number == erroLockWaitTimeout
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pgSerializationFailure || pqErr.Code == pgDeadlock
+	}
+
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		// o byte baixo é o código primário (SQLITE_BUSY_SNAPSHOT também é BUSY)
+		codigo := sqliteErr.Code() & 0xff
+		return codigo == sqlite3.SQLITE_BUSY || codigo == sqlite3.SQLITE_LOCKED
+	}
+	return false
+}
+
+// ComRetry executa a operação e repete em erros transitórios, com backoff
+// exponencial, até DB_RETRY_MAX tentativas (padrão 3). Não repete depois que
+// o contexto é cancelado. Uma escrita com várias etapas deve abrir a
+// transação dentro de operacao, para cada tentativa recomeçar do zero.
+func ComRetry(ctx context.Context, operacao func() error) error {
+	tentativas := envInt("DB_RETRY_MAX", 3)
+	espera := 100 * time.Millisecond
+
+	var err error
+	for tentativa := 1; ; tentativa++ {
+		err = operacao()
+		if err == nil || !ErroTransitorio(err) || tentativa >= tentativas || ctx.Err() != nil {
+			return err
+		}
+
+		slog.WarnContext(ctx, "erro transitório no banco, repetindo", "tentativa", tentativa, "tentativas", tentativas, "erro", err, "espera", espera)
+		select {
+		case <-time.After(espera):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		espera *= 2
+	}
+}
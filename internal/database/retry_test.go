@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+func TestErroTransitorio(t *testing.T) {
+	casos := []struct {
+		nome string
+		err  error
+		quer bool
+	}{
+		{"conexão descartada antes do envio", driver.ErrBadConn, true},
+		{"embrulhado", fmt.Errorf("erro ao inserir: %w", driver.ErrBadConn), true},
+		{"deadlock no MySQL", &mysql.MySQLError{Number: erroDeadlock}, true},
+		{"lock wait timeout no MySQL", &mysql.MySQLError{Number: erroLockWaitTimeout}, true},
+		{"deadlock no PostgreSQL", &pq.Error{Code: pgDeadlock}, true},
+		{"serialização no PostgreSQL", &pq.Error{Code: pgSerializationFailure}, true},
+		// Pode chegar depois de o servidor aplicar a escrita
+		{"conexão inválida no MySQL", mysql.ErrInvalidConn, false},
+		{"chave duplicada no MySQL", &mysql.MySQLError{Number: 1062}, false},
+		{"violação de unicidade no PostgreSQL", &pq.Error{Code: "23505"}, false},
+		{"contexto cancelado", context.Canceled, false},
+		{"outro erro", errors.New("falhou"), false},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if got := ErroTransitorio(c.err); got != c.quer {
+				t.Fatalf("ErroTransitorio(%v) = %v, esperado %v", c.err, got, c.quer)
+			}
+		})
+	}
+}
+
+func TestComRetry(t *testing.T) {
+	t.Setenv("DB_RETRY_MAX", "3")
+	ctx := context.Background()
+
+	casos := []struct {
+		nome       string
+		erros      []error
+		tentativas int
+		falha      bool
+	}{
+		{"sucesso de primeira", []error{nil}, 1, false},
+		{"transitório e depois sucesso", []error{driver.ErrBadConn, nil}, 2, false},
+		{"transitório até o limite", []error{driver.ErrBadConn, driver.ErrBadConn, driver.ErrBadConn, nil}, 3, true},
+		{"ErrInvalidConn não repete", []error{mysql.ErrInvalidConn, nil}, 1, true},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			tentativas := 0
+			err := ComRetry(ctx, func() error {
+				tentativas++
+				return c.erros[tentativas-1]
+			})
+			if tentativas != c.tentativas || (err != nil) != c.falha {
+				t.Fatalf("tentativas = %d, erro = %v; esperado %d tentativas, falha %v", tentativas, err, c.tentativas, c.falha)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	// driver em Go puro: o binário não depende de cgo
+	_ "modernc.org/sqlite"
+)
+
+// dsnSQLite usa o arquivo de DB_PATH (padrão concurso.db), em modo WAL
+func dsnSQLite() (string, error) {
+	caminho := os.Getenv("DB_PATH")
+	if caminho == "" {
+		caminho = "concurso.db"
+	}
+
+	timeout, err := envDuracao("DB_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("file:%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)", caminho, timeout.Milliseconds()), nil
+}
@@ -0,0 +1,123 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Tipos de evento publicados durante um job (GET /jobs/{id}/eventos)
+const (
+	EventoFase      = "fase"
+	EventoProgresso = "progresso"
+	EventoHeader    = "header"
+	EventoFooter    = "footer"
+	EventoRejeicao  = "rejeicao"
+	EventoResultado = "resultado"
+)
+
+// maxEventos limita o histórico guardado por job; quem conecta depois só
+// recebe os mais recentes
+const maxEventos = 1000
+
+// Evento é um acontecimento do job: mudança de fase, progresso, header/footer
+// encontrado, lote rejeitado ou o resultado final
+type Evento struct {
+	ID       int                    `json:"id"`
+	Tipo     string                 `json:"tipo"`
+	Mensagem string                 `json:"mensagem"`
+	Dados    map[string]interface{} `json:"dados,omitempty"`
+	Momento  time.Time              `json:"momento"`
+}
+
+// historico guarda os eventos de um job e avisa quem os acompanha
+type historico struct {
+	mu         sync.Mutex
+	eventos    []Evento
+	ultimoID   int
+	mudou      chan struct{}
+	finalizado bool
+}
+
+func novoHistorico() *historico {
+	return &historico{mudou: make(chan struct{})}
+}
+
+func (h *historico) publicar(tipo string, mensagem string, dados map[string]interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.adicionar(tipo, mensagem, dados)
+}
+
+// finalizar publica o resultado; depois dele nada mais é aceito
+func (h *historico) finalizar(mensagem string, dados map[string]interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.adicionar(EventoResultado, mensagem, dados)
+	h.finalizado = true
+}
+
+// adicionar grava o evento e acorda quem espera (chamado com mu travado)
+func (h *historico) adicionar(tipo string, mensagem string, dados map[string]interface{}) {
+	if h.finalizado {
+		return
+	}
+	h.ultimoID++
+	h.eventos = append(h.eventos, Evento{ID: h.ultimoID, Tipo: tipo, Mensagem: mensagem, Dados: dados, Momento: time.Now()})
+	if len(h.eventos) > maxEventos {
+		h.eventos = append([]Evento(nil), h.eventos[len(h.eventos)-maxEventos:]...)
+	}
+	close(h.mudou)
+	h.mudou = make(chan struct{})
+}
+
+// desde retorna os eventos com id maior que ultimo, o canal fechado no
+// próximo evento e se o job já terminou
+func (h *historico) desde(ultimo int) ([]Evento, <-chan struct{}, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var novos []Evento
+	for _, evento := range h.eventos {
+		if evento.ID > ultimo {
+			novos = append(novos, evento)
+		}
+	}
+	return novos, h.mudou, h.finalizado
+}
+
+type chaveContexto int
+
+const chaveHistorico chaveContexto = iota
+
+// Publicar registra um evento no job dono de ctx (sem job, não faz nada).
+// args segue o formato chave/valor do slog, para que o evento tenha os
+// mesmos atributos da linha de log correspondente.
+func Publicar(ctx context.Context, tipo string, mensagem string, args ...any) {
+	h, ok := ctx.Value(chaveHistorico).(*historico)
+	if !ok {
+		return
+	}
+	h.publicar(tipo, mensagem, dadosDe(args))
+}
+
+// dadosDe converte pares chave/valor do slog em mapa serializável
+func dadosDe(args []any) map[string]interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+	registro := slog.NewRecord(time.Time{}, slog.LevelInfo, "", 0)
+	registro.Add(args...)
+	dados := make(map[string]interface{}, registro.NumAttrs())
+	registro.Attrs(func(a slog.Attr) bool {
+		valor := a.Value.Resolve()
+		switch valor.Kind() {
+		case slog.KindDuration:
+			dados[a.Key] = valor.Duration().String()
+		default:
+			dados[a.Key] = valor.Any()
+		}
+		return true
+	})
+	return dados
+}
@@ -0,0 +1,230 @@
+package jobs
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"concurso-go-app/internal/metricas"
+)
+
+// Status de um job
+const (
+	StatusExecutando = "executando"
+	StatusSucesso    = "sucesso"
+	StatusFalha      = "falha"
+	StatusCancelado  = "cancelado"
+)
+
+// Tipos de job (um por endpoint do pipeline)
+const (
+	TipoGeracao  = "geracao"
+	TipoExtracao = "extracao"
+	TipoConsumo  = "consumo"
+)
+
+var (
+	// ErrJobNaoEncontrado indica id desconhecido (ou job já descartado)
+	ErrJobNaoEncontrado = errors.New("job não encontrado")
+	// ErrJobFinalizado indica que o job já terminou e não pode ser cancelado
+	ErrJobFinalizado = errors.New("job já finalizado")
+	// ErrEncerrando indica que o servidor está desligando e não aceita jobs
+	ErrEncerrando = errors.New("servidor em encerramento, novos jobs recusados")
+)
+
+// retencao é por quanto tempo um job finalizado continua consultável
+const retencao = time.Hour
+
+// Job é uma execução do pipeline (geração, extração ou consumo)
+type Job struct {
+	ID     string     `json:"id"`
+	Tipo   string     `json:"tipo"`
+	Data   string     `json:"data,omitempty"`
+	Status string     `json:"status"`
+	Erro   string     `json:"erro,omitempty"`
+	Inicio time.Time  `json:"inicio"`
+	Fim    *time.Time `json:"fim,omitempty"`
+
+	cancelar context.CancelFunc
+	eventos  *historico
+}
+
+// Gerenciador guarda os jobs em memória e permite cancelá-los pelo id
+type Gerenciador struct {
+	mu         sync.Mutex
+	jobs       map[string]*Job
+	wg         sync.WaitGroup
+	encerrando bool
+}
+
+func NovoGerenciador() *Gerenciador {
+	return &Gerenciador{jobs: make(map[string]*Job)}
+}
+
+// Iniciar registra um job e devolve o contexto que ele deve usar: é
+// cancelado por Cancelar ou quando ctx (ex.: a requisição) termina, e leva o
+// histórico para Publicar. Todo job iniciado precisa de um Finalizar. Depois
+// de Encerrar retorna ErrEncerrando.
+func (g *Gerenciador) Iniciar(ctx context.Context, tipo string, data string) (Job, context.Context, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.encerrando {
+		return Job{}, nil, ErrEncerrando
+	}
+
+	ctx, cancelar := context.WithCancel(ctx)
+	job := &Job{ID: novoID(), Tipo: tipo, Data: data, Status: StatusExecutando, Inicio: time.Now(), cancelar: cancelar, eventos: novoHistorico()}
+	ctx = context.WithValue(ctx, chaveHistorico, job.eventos)
+	g.descartarAntigos()
+	g.jobs[job.ID] = job
+	g.wg.Add(1)
+	metricas.JobsAtivos.WithLabelValues(tipo).Inc()
+	return *job, ctx, nil
+}
+
+// Finalizar grava o resultado: sucesso se err for nil, cancelado se o
+// contexto do job foi cancelado, falha nos demais casos. resultado (a
+// resposta da etapa, pode ser nil) vai no evento final.
+func (g *Gerenciador) Finalizar(id string, resultado map[string]interface{}, err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	job, ok := g.jobs[id]
+	if !ok || job.Fim != nil {
+		return
+	}
+
+	fim := time.Now()
+	job.Fim = &fim
+	switch {
+	case err == nil:
+		job.Status = StatusSucesso
+	case errors.Is(err, context.Canceled):
+		job.Status = StatusCancelado
+		job.Erro = err.Error()
+	default:
+		job.Status = StatusFalha
+		job.Erro = err.Error()
+	}
+	job.cancelar()
+
+	dados := map[string]interface{}{"status": job.Status, "duracao": fim.Sub(job.Inicio).String()}
+	if job.Erro != "" {
+		dados["erro"] = job.Erro
+	}
+	if resultado != nil {
+		dados["resultado"] = resultado
+	}
+	job.eventos.finalizar("job "+job.Status, dados)
+
+	metricas.JobsAtivos.WithLabelValues(job.Tipo).Dec()
+	g.wg.Done()
+}
+
+// Cancelar pede o cancelamento do job; ele termina quando o serviço perceber
+// o contexto cancelado
+func (g *Gerenciador) Cancelar(id string) (Job, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	job, ok := g.jobs[id]
+	if !ok {
+		return Job{}, ErrJobNaoEncontrado
+	}
+	if job.Fim != nil {
+		return *job, ErrJobFinalizado
+	}
+	job.cancelar()
+	return *job, nil
+}
+
+// CancelarTodos cancela os jobs em execução e retorna quantos eram
+func (g *Gerenciador) CancelarTodos() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	total := 0
+	for _, job := range g.jobs {
+		if job.Fim == nil {
+			job.cancelar()
+			total++
+		}
+	}
+	return total
+}
+
+// Encerrar passa a recusar novos jobs; os em execução continuam
+func (g *Gerenciador) Encerrar() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.encerrando = true
+}
+
+// Aguardar espera os jobs em execução finalizarem ou ctx terminar; retorna
+// false se ainda havia jobs rodando
+func (g *Gerenciador) Aguardar(ctx context.Context) bool {
+	feito := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(feito)
+	}()
+	select {
+	case <-feito:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Obter retorna uma cópia do job
+func (g *Gerenciador) Obter(id string) (Job, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	job, ok := g.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// Eventos retorna os eventos do job com id maior que ultimo, um canal
+// fechado quando houver outro e se o job já terminou (não há mais eventos)
+func (g *Gerenciador) Eventos(id string, ultimo int) ([]Evento, <-chan struct{}, bool, error) {
+	g.mu.Lock()
+	job, ok := g.jobs[id]
+	g.mu.Unlock()
+	if !ok {
+		return nil, nil, false, ErrJobNaoEncontrado
+	}
+	eventos, mudou, finalizado := job.eventos.desde(ultimo)
+	return eventos, mudou, finalizado, nil
+}
+
+// Listar retorna os jobs do mais recente para o mais antigo
+func (g *Gerenciador) Listar() []Job {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	lista := make([]Job, 0, len(g.jobs))
+	for _, job := range g.jobs {
+		lista = append(lista, *job)
+	}
+	sort.Slice(lista, func(i, j int) bool { return lista[i].Inicio.After(lista[j].Inicio) })
+	return lista
+}
+
+// descartarAntigos remove jobs finalizados há mais de retencao (chamado com mu travado)
+func (g *Gerenciador) descartarAntigos() {
+	limite := time.Now().Add(-retencao)
+	for id, job := range g.jobs {
+		if job.Fim != nil && job.Fim.Before(limite) {
+			delete(g.jobs, id)
+		}
+	}
+}
+
+func novoID() string {
+	b := make([]byte, 8)
+	cryptorand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,58 @@
+package kafka
+
+import "sync"
+
+// abertos guarda os produtores e consumidores em uso, para o encerramento
+// do servidor fechar todos (e não só o último criado)
+var abertos = &clientesAbertos{
+	produtoresAbertos:   make(map[*Produtor]struct{}),
+	consumidoresAbertos: make(map[*Consumidor]struct{}),
+}
+
+type clientesAbertos struct {
+	mu                  sync.Mutex
+	produtoresAbertos   map[*Produtor]struct{}
+	consumidoresAbertos map[*Consumidor]struct{}
+}
+
+func (a *clientesAbertos) registrar(cliente interface{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch c := cliente.(type) {
+	case *Produtor:
+		a.produtoresAbertos[c] = struct{}{}
+	case *Consumidor:
+		a.consumidoresAbertos[c] = struct{}{}
+	}
+}
+
+func (a *clientesAbertos) remover(cliente interface{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch c := cliente.(type) {
+	case *Produtor:
+		delete(a.produtoresAbertos, c)
+	case *Consumidor:
+		delete(a.consumidoresAbertos, c)
+	}
+}
+
+func (a *clientesAbertos) produtores() []*Produtor {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	lista := make([]*Produtor, 0, len(a.produtoresAbertos))
+	for p := range a.produtoresAbertos {
+		lista = append(lista, p)
+	}
+	return lista
+}
+
+func (a *clientesAbertos) consumidores() []*Consumidor {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	lista := make([]*Consumidor, 0, len(a.consumidoresAbertos))
+	for c := range a.consumidoresAbertos {
+		lista = append(lista, c)
+	}
+	return lista
+}
@@ -0,0 +1,195 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"golang.org/x/sync/singleflight"
+)
+
+// NumeroParticoes retorna a quantidade de partições usada na criação dos tópicos
+func NumeroParticoes() int32 {
+	particoes, err := strconv.Atoi(os.Getenv("KAFKA_PARTITIONS"))
+	if err != nil || particoes < 1 {
+		return 3
+	}
+	return int32(particoes)
+}
+
+func fatorReplicacao() int16 {
+	replicacao, err := strconv.Atoi(os.Getenv("KAFKA_REPLICATION_FACTOR"))
+	if err != nil || replicacao < 1 {
+		return 1
+	}
+	return int16(replicacao)
+}
+
+// novoAdmin cria um cluster admin com a configuração compartilhada
+func novoAdmin() (sarama.ClusterAdmin, error) {
+	config, err := NovaConfig()
+	if err != nil {
+		return nil, err
+	}
+	return sarama.NewClusterAdmin(Brokers(), config)
+}
+
+// verificacoes junta as chamadas simultâneas numa só verificação: com dois
+// probes (kubelet e balanceador, por exemplo) o segundo recebe o resultado do
+// primeiro em vez de abrir outro client
+var verificacoes singleflight.Group
+
+// tempoMaximoVerificacao limita a verificação quando quem chama não tem prazo
+const tempoMaximoVerificacao = 5 * time.Second
+
+// dialerContexto abre as conexões do client com o contexto da verificação:
+// cancelado o contexto, a conexão em andamento é abandonada
+type dialerContexto struct {
+	ctx    context.Context
+	dialer net.Dialer
+}
+
+func (d dialerContexto) Dial(network, addr string) (net.Conn, error) {
+	return d.dialer.DialContext(d.ctx, network, addr)
+}
+
+// VerificarBrokers abre um client só para buscar os metadados do cluster e
+// retorna quantos brokers responderam. Usado pelo /readyz.
+func VerificarBrokers(ctx context.Context) (int, error) {
+	prazo := time.Now().Add(tempoMaximoVerificacao)
+	if limite, ok := ctx.Deadline(); ok && limite.Before(prazo) {
+		prazo = limite
+	}
+	resultado := verificacoes.DoChan("brokers", func() (interface{}, error) {
+		// Contexto próprio: quem chegou primeiro pode desistir sem derrubar
+		// quem está esperando o mesmo resultado
+		ctxVerificacao, cancelar := context.WithDeadline(context.WithoutCancel(ctx), prazo)
+		defer cancelar()
+		return verificarBrokers(ctxVerificacao)
+	})
+
+	select {
+	case r := <-resultado:
+		if r.Err != nil {
+			return 0, r.Err
+		}
+		return r.Val.(int), nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// verificarBrokers faz a verificação dentro do prazo de ctx, que vale para a
+// conexão e para as leituras e escritas
+func verificarBrokers(ctx context.Context) (int, error) {
+	config, err := NovaConfig()
+	if err != nil {
+		return 0, err
+	}
+	config.Metadata.Retry.Max = 0
+	config.Net.DialTimeout = 2 * time.Second
+	config.Net.Proxy.Enable = true
+	config.Net.Proxy.Dialer = dialerContexto{ctx: ctx, dialer: net.Dialer{Timeout: config.Net.DialTimeout, KeepAlive: config.Net.KeepAlive}}
+	if prazo, ok := ctx.Deadline(); ok {
+		restante := time.Until(prazo)
+		config.Net.ReadTimeout = restante
+		config.Net.WriteTimeout = restante
+	}
+
+	client, err := sarama.NewClient(Brokers(), config)
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+	if err := client.RefreshMetadata(); err != nil {
+		return 0, err
+	}
+	return len(client.Brokers()), nil
+}
+
+// GarantirTopico cria o tópico com o número de partições configurado caso ele
+// não exista. Um tópico existente com menos partições que KAFKA_PARTITIONS
+// ganha as que faltam; com mais, fica como está.
+func GarantirTopico(topic string) error {
+	admin, err := novoAdmin()
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	err = admin.CreateTopic(topic, &sarama.TopicDetail{
+		NumPartitions:     NumeroParticoes(),
+		ReplicationFactor: fatorReplicacao(),
+	}, false)
+
+	if errors.Is(err, sarama.ErrTopicAlreadyExists) {
+		return completarParticoes(admin, topic)
+	}
+	if err != nil {
+		return err
+	}
+
+	slog.Info("tópico criado", "topico", topic, "particoes", NumeroParticoes())
+	return nil
+}
+
+// completarParticoes aumenta o tópico existente até NumeroParticoes partições
+func completarParticoes(admin sarama.ClusterAdmin, topic string) error {
+	metadados, err := admin.DescribeTopics([]string{topic})
+	if err != nil {
+		return fmt.Errorf("erro ao descrever o tópico %s: %v", topic, err)
+	}
+	if len(metadados) == 0 {
+		return fmt.Errorf("tópico %s não encontrado", topic)
+	}
+	if metadados[0].Err != sarama.ErrNoError {
+		return fmt.Errorf("erro ao descrever o tópico %s: %v", topic, metadados[0].Err)
+	}
+
+	atuais := int32(len(metadados[0].Partitions))
+	if atuais >= NumeroParticoes() {
+		return nil
+	}
+	if err := admin.CreatePartitions(topic, NumeroParticoes(), nil, false); err != nil {
+		return fmt.Errorf("tópico %s tem %d partição(ões), menos que KAFKA_PARTITIONS=%d, e não foi possível aumentar: %v", topic, atuais, NumeroParticoes(), err)
+	}
+
+	slog.Info("partições adicionadas ao tópico", "topico", topic, "antes", atuais, "particoes", NumeroParticoes())
+	return nil
+}
+
+// RecriarTopico apaga o tópico (se existir) e cria de novo, vazio
+func RecriarTopico(topic string) error {
+	admin, err := novoAdmin()
+	if err != nil {
+		return err
+	}
+	defer admin.Close()
+
+	err = admin.DeleteTopic(topic)
+	if err != nil && !errors.Is(err, sarama.ErrUnknownTopicOrPartition) {
+		return err
+	}
+
+	// A exclusão é assíncrona no broker: tentar criar até ela terminar
+	detalhe := &sarama.TopicDetail{NumPartitions: NumeroParticoes(), ReplicationFactor: fatorReplicacao()}
+	for tentativa := 1; ; tentativa++ {
+		err = admin.CreateTopic(topic, detalhe, false)
+		if !errors.Is(err, sarama.ErrTopicAlreadyExists) || tentativa == 10 {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	if err != nil {
+		return err
+	}
+
+	slog.Info("tópico recriado", "topico", topic, "particoes", NumeroParticoes())
+	return nil
+}
@@ -0,0 +1,113 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// brokerMudo aceita conexões e nunca responde, como um broker travado. A
+// verificação de um teste anterior pode ainda estar em andamento: Forget faz
+// este teste começar outra em vez de receber o resultado dela.
+func brokerMudo(t *testing.T) (string, *int32) {
+	t.Helper()
+	verificacoes.Forget("brokers")
+	ouvinte, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conexoes int32
+	var abertas []net.Conn
+	var mu sync.Mutex
+	go func() {
+		for {
+			conexao, err := ouvinte.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&conexoes, 1)
+			mu.Lock()
+			abertas = append(abertas, conexao)
+			mu.Unlock()
+		}
+	}()
+	t.Cleanup(func() {
+		ouvinte.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for _, conexao := range abertas {
+			conexao.Close()
+		}
+	})
+	return ouvinte.Addr().String(), &conexoes
+}
+
+// Chamadas simultâneas (kubelet e balanceador) esperam a mesma verificação em
+// vez de uma delas falhar por haver outra em andamento
+func TestVerificarBrokersCompartilhaVerificacao(t *testing.T) {
+	endereco, conexoes := brokerMudo(t)
+	t.Setenv("KAFKA_BROKERS", endereco)
+
+	ctx, cancelar := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancelar()
+
+	erros := make([]error, 3)
+	var wg sync.WaitGroup
+	for i := range erros {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, erros[i] = VerificarBrokers(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range erros {
+		if err == nil {
+			t.Fatalf("chamada %d: esperava erro com o broker mudo", i)
+		}
+	}
+	if n := atomic.LoadInt32(conexoes); n != 1 {
+		t.Fatalf("%d conexões abertas, esperado 1 compartilhada", n)
+	}
+}
+
+// Quem chegou primeiro desistir não derruba quem espera o mesmo resultado
+func TestVerificarBrokersPrimeiroDesiste(t *testing.T) {
+	endereco, _ := brokerMudo(t)
+	t.Setenv("KAFKA_BROKERS", endereco)
+
+	primeiro, cancelarPrimeiro := context.WithCancel(context.Background())
+	feito := make(chan error, 1)
+	go func() {
+		_, err := VerificarBrokers(primeiro)
+		feito <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	segundo, cancelarSegundo := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancelarSegundo()
+	resultado := make(chan error, 1)
+	go func() {
+		_, err := VerificarBrokers(segundo)
+		resultado <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	cancelarPrimeiro()
+	if err := <-feito; !errors.Is(err, context.Canceled) {
+		t.Fatalf("primeiro = %v, esperado cancelado", err)
+	}
+	select {
+	case err := <-resultado:
+		t.Fatalf("segundo terminou junto com o primeiro: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if err := <-resultado; err == nil {
+		t.Fatal("segundo: esperava erro com o broker mudo")
+	}
+}
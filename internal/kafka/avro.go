@@ -0,0 +1,170 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"concurso-go-app/internal/models"
+)
+
+// codificadorAvro implementa o binary encoding do Avro para os tipos usados
+// nas mensagens: string, long e uniões ["null", T] para campos opcionais
+type codificadorAvro struct{}
+
+type avroRecord struct {
+	Type      string      `json:"type"`
+	Name      string      `json:"name"`
+	Namespace string      `json:"namespace,omitempty"`
+	Fields    []avroCampo `json:"fields"`
+}
+
+type avroCampo struct {
+	Name    string          `json:"name"`
+	Type    json.RawMessage `json:"type"`
+	Default json.RawMessage `json:"default,omitempty"`
+}
+
+// avroTipoJSON marca strings que carregam JSON (logicalType desconhecido é ignorado por outros leitores)
+const avroTipoJSON = `{"type":"string","logicalType":"json"}`
+
+func (codificadorAvro) tipoEsquema() string {
+	return TipoEsquemaAvro
+}
+
+func (codificadorAvro) textoEsquema(esquema models.Esquema) string {
+	record := avroRecord{
+		Type:      "record",
+		Name:      esquema.Nome,
+		Namespace: models.NamespaceEsquemas,
+	}
+
+	for _, campo := range esquema.Campos {
+		tipo := `"` + campo.Tipo + `"`
+		if campo.Tipo == models.CampoJSON {
+			tipo = avroTipoJSON
+		}
+
+		avro := avroCampo{Name: campo.Nome, Type: json.RawMessage(tipo)}
+		if campo.Opcional {
+			avro.Type = json.RawMessage(`["null",` + tipo + `]`)
+			avro.Default = json.RawMessage("null")
+		}
+		record.Fields = append(record.Fields, avro)
+	}
+
+	texto, _ := json.Marshal(record)
+	return string(texto)
+}
+
+func (codificadorAvro) lerEsquema(texto string) (models.Esquema, error) {
+	var record avroRecord
+	if err := json.Unmarshal([]byte(texto), &record); err != nil {
+		return models.Esquema{}, err
+	}
+	if record.Type != "record" {
+		return models.Esquema{}, fmt.Errorf("esquema avro deveria ser record, veio %s", record.Type)
+	}
+
+	esquema := models.Esquema{Nome: record.Name}
+	for _, avro := range record.Fields {
+		campo := models.Campo{Nome: avro.Name}
+
+		tipo := avro.Type
+		var uniao []json.RawMessage
+		if json.Unmarshal(avro.Type, &uniao) == nil {
+			if len(uniao) != 2 || string(uniao[0]) != `"null"` {
+				return models.Esquema{}, fmt.Errorf("união não suportada no campo %s", avro.Name)
+			}
+			campo.Opcional = true
+			tipo = uniao[1]
+		}
+
+		var simples string
+		var composto struct {
+			Type        string `json:"type"`
+			LogicalType string `json:"logicalType"`
+		}
+		switch {
+		case json.Unmarshal(tipo, &simples) == nil:
+			campo.Tipo = simples
+		case json.Unmarshal(tipo, &composto) == nil && composto.Type == "string" && composto.LogicalType == "json":
+			campo.Tipo = models.CampoJSON
+		default:
+			return models.Esquema{}, fmt.Errorf("tipo não suportado no campo %s: %s", avro.Name, tipo)
+		}
+		if campo.Tipo != models.CampoString && campo.Tipo != models.CampoLong && campo.Tipo != models.CampoJSON {
+			return models.Esquema{}, fmt.Errorf("tipo não suportado no campo %s: %s", avro.Name, campo.Tipo)
+		}
+
+		esquema.Campos = append(esquema.Campos, campo)
+	}
+	return esquema, nil
+}
+
+func (codificadorAvro) codificar(esquema models.Esquema, valores map[string]interface{}) ([]byte, error) {
+	var dados []byte
+	for _, campo := range esquema.Campos {
+		valor := valores[campo.Nome]
+		if campo.Opcional {
+			// Índice da união: 0 = null, 1 = valor
+			if valor == nil {
+				dados = binary.AppendVarint(dados, 0)
+				continue
+			}
+			dados = binary.AppendVarint(dados, 1)
+		}
+
+		switch v := valor.(type) {
+		case string:
+			dados = binary.AppendVarint(dados, int64(len(v)))
+			dados = append(dados, v...)
+		case int64:
+			dados = binary.AppendVarint(dados, v)
+		default:
+			return nil, fmt.Errorf("valor inválido no campo %s", campo.Nome)
+		}
+	}
+	return dados, nil
+}
+
+func (codificadorAvro) decodificar(esquema models.Esquema, dados []byte) (map[string]interface{}, error) {
+	lerLong := func() (int64, error) {
+		valor, n := binary.Varint(dados)
+		if n <= 0 {
+			return 0, fmt.Errorf("long inválido")
+		}
+		dados = dados[n:]
+		return valor, nil
+	}
+
+	valores := make(map[string]interface{}, len(esquema.Campos))
+	for _, campo := range esquema.Campos {
+		if campo.Opcional {
+			indice, err := lerLong()
+			if err != nil {
+				return nil, err
+			}
+			if indice == 0 {
+				valores[campo.Nome] = nil
+				continue
+			}
+		}
+
+		numero, err := lerLong()
+		if err != nil {
+			return nil, fmt.Errorf("campo %s: %v", campo.Nome, err)
+		}
+		if campo.Tipo == models.CampoLong {
+			valores[campo.Nome] = numero
+			continue
+		}
+
+		if numero < 0 || numero > int64(len(dados)) {
+			return nil, fmt.Errorf("campo %s: tamanho inválido", campo.Nome)
+		}
+		valores[campo.Nome] = valorDecodificado(campo, string(dados[:numero]))
+		dados = dados[numero:]
+	}
+	return valores, nil
+}
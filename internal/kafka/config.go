@@ -0,0 +1,145 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Shopify/sarama"
+	"github.com/xdg-go/scram"
+)
+
+// Brokers retorna a lista de brokers de KAFKA_BROKERS (separados por vírgula).
+// KAFKA_BROKER continua aceito para um broker só.
+func Brokers() []string {
+	lista := os.Getenv("KAFKA_BROKERS")
+	if lista == "" {
+		lista = os.Getenv("KAFKA_BROKER")
+	}
+
+	var brokers []string
+	for _, broker := range strings.Split(lista, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			brokers = append(brokers, broker)
+		}
+	}
+	if len(brokers) == 0 {
+		brokers = []string{"localhost:9092"}
+	}
+	return brokers
+}
+
+// NovaConfig monta a configuração sarama usada por produtor, consumidor e
+// admin: client ID, versão do Kafka, TLS e SASL conforme as variáveis KAFKA_*
+func NovaConfig() (*sarama.Config, error) {
+	config := sarama.NewConfig()
+
+	config.ClientID = os.Getenv("KAFKA_CLIENT_ID")
+	if config.ClientID == "" {
+		config.ClientID = "concurso-go-app"
+	}
+
+	if versao := os.Getenv("KAFKA_VERSION"); versao != "" {
+		version, err := sarama.ParseKafkaVersion(versao)
+		if err != nil {
+			return nil, fmt.Errorf("%w: KAFKA_VERSION inválida: %v", ErrConfiguracao, err)
+		}
+		config.Version = version
+	}
+
+	if envBool("KAFKA_TLS_ENABLED") {
+		tlsConfig, err := configTLS()
+		if err != nil {
+			return nil, err
+		}
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
+	if mecanismo := strings.ToUpper(os.Getenv("KAFKA_SASL_MECHANISM")); mecanismo != "" {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.User = os.Getenv("KAFKA_SASL_USERNAME")
+		config.Net.SASL.Password = os.Getenv("KAFKA_SASL_PASSWORD")
+
+		switch mecanismo {
+		case sarama.SASLTypePlaintext:
+			config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		case sarama.SASLTypeSCRAMSHA256:
+			config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &clienteSCRAM{hash: scram.SHA256}
+			}
+		case sarama.SASLTypeSCRAMSHA512:
+			config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &clienteSCRAM{hash: scram.SHA512}
+			}
+		default:
+			return nil, fmt.Errorf("%w: KAFKA_SASL_MECHANISM não suportado: %s (use PLAIN, SCRAM-SHA-256 ou SCRAM-SHA-512)", ErrConfiguracao, mecanismo)
+		}
+	}
+
+	return config, nil
+}
+
+// configTLS carrega a CA e o certificado de cliente (mTLS) configurados
+func configTLS() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: envBool("KAFKA_TLS_INSECURE_SKIP_VERIFY"), // Só para desenvolvimento
+	}
+
+	if caFile := os.Getenv("KAFKA_TLS_CA_FILE"); caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: erro ao ler CA do Kafka: %v", ErrConfiguracao, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("%w: nenhum certificado válido em %s", ErrConfiguracao, caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	certFile, keyFile := os.Getenv("KAFKA_TLS_CERT_FILE"), os.Getenv("KAFKA_TLS_KEY_FILE")
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: erro ao carregar certificado de cliente do Kafka: %v", ErrConfiguracao, err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func envBool(nome string) bool {
+	valor, _ := strconv.ParseBool(os.Getenv(nome))
+	return valor
+}
+
+// clienteSCRAM implementa sarama.SCRAMClient com xdg-go/scram
+type clienteSCRAM struct {
+	hash     scram.HashGeneratorFcn
+	conversa *scram.ClientConversation
+}
+
+func (c *clienteSCRAM) Begin(usuario, senha, authzID string) error {
+	client, err := c.hash.NewClient(usuario, senha, authzID)
+	if err != nil {
+		return err
+	}
+	c.conversa = client.NewConversation()
+	return nil
+}
+
+func (c *clienteSCRAM) Step(desafio string) (string, error) {
+	return c.conversa.Step(desafio)
+}
+
+func (c *clienteSCRAM) Done() bool {
+	return c.conversa.Done()
+}
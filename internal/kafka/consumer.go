@@ -1,48 +1,168 @@
 package kafka
 
 import (
-	"log"
-	"os"
+	"context"
+	"log/slog"
+	"sync"
+
+	"concurso-go-app/internal/rastreamento"
 
 	"github.com/Shopify/sarama"
 )
 
-var Consumer sarama.Consumer
+// Mensagem representa uma mensagem lida de uma partição
+type Mensagem struct {
+	Particao int32
+	Offset   int64
+	Chave    []byte
+	Headers  map[string]string
+	Valor    []byte
+}
 
-func InitConsumer() error {
-	broker := os.Getenv("KAFKA_BROKER")
-	if broker == "" {
-		broker = "localhost:9092"
+// Metadados retorna os metadados gravados nos headers da mensagem.
+// Mensagens antigas, sem headers, retornam metadados vazios.
+func (m Mensagem) Metadados() Metadados {
+	return Metadados{
+		Lote:    m.Headers[HeaderLote],
+		Tipo:    m.Headers[HeaderTipo],
+		Data:    m.Headers[HeaderData],
+		TraceID: m.Headers[HeaderTraceID],
 	}
+}
 
-	var err error
-	Consumer, err = sarama.NewConsumer([]string{broker}, nil)
+// Contexto devolve ctx continuando o trace gravado nos headers pelo produtor
+func (m Mensagem) Contexto(ctx context.Context) context.Context {
+	return rastreamento.Extrair(ctx, m.Headers)
+}
+
+// Consumidor lê as partições de um tópico com client próprio; cada job de
+// consumo cria o seu com NovoConsumidor e o fecha no fim
+type Consumidor struct {
+	consumer sarama.Consumer
+	client   sarama.Client
+	fechar   sync.Once
+}
+
+func NovoConsumidor() (*Consumidor, error) {
+	config, err := NovaConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := sarama.NewClient(Brokers(), config)
 	if err != nil {
+		return nil, err
+	}
+
+	consumer, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	c := &Consumidor{consumer: consumer, client: client}
+	abertos.registrar(c)
+	slog.Debug("consumidor Kafka inicializado")
+	return c, nil
+}
+
+// ConsumeMessages consome todas as partições do tópico em paralelo.
+// Cada partição é lida até o handler retornar true ou até o fim das
+// mensagens existentes no início do consumo. O handler é chamado
+// concorrentemente por partições diferentes, mas em ordem dentro de cada uma.
+// Cancelar o contexto interrompe todas as partições e retorna ctx.Err().
+func (c *Consumidor) ConsumeMessages(ctx context.Context, topic string, handler func(Mensagem) bool) error {
+	partitions, err := c.consumer.Partitions(topic)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	erros := make(chan error, len(partitions))
+
+	for _, partition := range partitions {
+		wg.Add(1)
+		go func(partition int32) {
+			defer wg.Done()
+			if err := c.consumirParticao(ctx, topic, partition, handler); err != nil {
+				erros <- err
+			}
+		}(partition)
+	}
+
+	wg.Wait()
+	close(erros)
+
+	if err := ctx.Err(); err != nil {
 		return err
 	}
 
-	log.Println("Consumidor Kafka inicializado com sucesso")
+	// Retorna o primeiro erro encontrado
+	for err := range erros {
+		return err
+	}
 	return nil
 }
 
-func ConsumeMessages(topic string, handler func([]byte) bool) error {
-	partitionConsumer, err := Consumer.ConsumePartition(topic, 0, sarama.OffsetOldest)
+func (c *Consumidor) consumirParticao(ctx context.Context, topic string, partition int32, handler func(Mensagem) bool) error {
+	// Offset da próxima mensagem a ser produzida: consumir até ele
+	ultimoOffset, err := c.client.GetOffset(topic, partition, sarama.OffsetNewest)
+	if err != nil {
+		return err
+	}
+	primeiroOffset, err := c.client.GetOffset(topic, partition, sarama.OffsetOldest)
+	if err != nil {
+		return err
+	}
+	if ultimoOffset <= primeiroOffset {
+		slog.DebugContext(ctx, "partição vazia, nada a consumir", "particao", partition)
+		return nil
+	}
+
+	partitionConsumer, err := c.consumer.ConsumePartition(topic, partition, sarama.OffsetOldest)
 	if err != nil {
 		return err
 	}
 	defer partitionConsumer.Close()
 
 	messageCount := 0
-	for message := range partitionConsumer.Messages() {
+	for {
+		var message *sarama.ConsumerMessage
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "consumo da partição cancelado", "particao", partition, "mensagens", messageCount)
+			return nil
+		case message = <-partitionConsumer.Messages():
+		}
+		if message == nil {
+			break
+		}
 		messageCount++
 
-		// Log a cada 10K mensagens para debug
 		if messageCount%10000 == 0 {
-			log.Printf("Processadas %d mensagens, continuando...", messageCount)
+			slog.DebugContext(ctx, "mensagens processadas na partição", "particao", partition, "mensagens", messageCount)
+		}
+
+		headers := make(map[string]string, len(message.Headers))
+		for _, header := range message.Headers {
+			headers[string(header.Key)] = string(header.Value)
+		}
+
+		msg := Mensagem{
+			Particao: message.Partition,
+			Offset:   message.Offset,
+			Chave:    message.Key,
+			Headers:  headers,
+			Valor:    message.Value,
+		}
+
+		if handler(msg) {
+			slog.DebugContext(ctx, "handler encerrou o consumo da partição", "particao", partition, "mensagens", messageCount)
+			break
 		}
 
-		if handler(message.Value) {
-			log.Printf("Handler retornou true, parando consumo após %d mensagens", messageCount)
+		if message.Offset >= ultimoOffset-1 {
+			slog.DebugContext(ctx, "fim das mensagens disponíveis na partição", "particao", partition, "mensagens", messageCount)
 			break
 		}
 	}
@@ -50,8 +170,22 @@ func ConsumeMessages(topic string, handler func([]byte) bool) error {
 	return nil
 }
 
+// Close fecha o consumidor e o client; pode ser chamado mais de uma vez
+func (c *Consumidor) Close() error {
+	var err error
+	c.fechar.Do(func() {
+		abertos.remover(c)
+		err = c.consumer.Close()
+		if errClient := c.client.Close(); err == nil {
+			err = errClient
+		}
+	})
+	return err
+}
+
+// CloseConsumer fecha todos os consumidores ainda abertos (encerramento do servidor)
 func CloseConsumer() {
-	if Consumer != nil {
-		Consumer.Close()
+	for _, c := range abertos.consumidores() {
+		c.Close()
 	}
 }
@@ -0,0 +1,50 @@
+package kafka
+
+import (
+	"errors"
+	"net"
+
+	"github.com/Shopify/sarama"
+)
+
+// Erros que tentar de novo não resolve: é preciso corrigir a configuração ou
+// o esquema. Use errors.Is.
+var (
+	// ErrConfiguracao indica variável KAFKA_* ou SCHEMA_REGISTRY_URL inválida
+	ErrConfiguracao = errors.New("configuração do Kafka inválida")
+	// ErrEsquemaIncompativel indica esquema recusado pela regra de compatibilidade
+	ErrEsquemaIncompativel = errors.New("esquema incompatível")
+)
+
+// errosDisponibilidade são as respostas do cluster que passam sozinhas
+// (eleição de líder, broker reiniciando, réplicas atrasadas)
+var errosDisponibilidade = []error{
+	sarama.ErrOutOfBrokers,
+	sarama.ErrNotConnected,
+	sarama.ErrBrokerNotAvailable,
+	sarama.ErrLeaderNotAvailable,
+	sarama.ErrNotLeaderForPartition,
+	sarama.ErrRequestTimedOut,
+	sarama.ErrNotEnoughReplicas,
+	sarama.ErrNotEnoughReplicasAfterAppend,
+	sarama.ErrNetworkException,
+}
+
+// BrokerIndisponivel indica que o erro é de disponibilidade do cluster
+// (nenhum broker respondeu, conexão recusada ou sem resposta): tentar mais
+// tarde pode resolver
+func BrokerIndisponivel(err error) bool {
+	for _, disponibilidade := range errosDisponibilidade {
+		if errors.Is(err, disponibilidade) {
+			return true
+		}
+	}
+	var erroRede net.Error
+	return errors.As(err, &erroRede)
+}
+
+// TopicoInexistente indica que o tópico (ou a partição) não existe no cluster,
+// como ao consumir uma data que nunca foi extraída
+func TopicoInexistente(err error) bool {
+	return errors.Is(err, sarama.ErrUnknownTopicOrPartition)
+}
@@ -0,0 +1,41 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/Shopify/sarama"
+)
+
+func TestClassificacaoErros(t *testing.T) {
+	recusada := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	casos := []struct {
+		nome         string
+		err          error
+		indisponivel bool
+		inexistente  bool
+	}{
+		{"sem brokers", sarama.ErrOutOfBrokers, true, false},
+		{"sem brokers com causa", sarama.Wrap(sarama.ErrOutOfBrokers, recusada), true, false},
+		{"conexão recusada", fmt.Errorf("erro ao produzir: %w", recusada), true, false},
+		{"líder indisponível", sarama.ErrLeaderNotAvailable, true, false},
+		{"tempo esgotado no broker", sarama.ErrRequestTimedOut, true, false},
+		{"tópico inexistente", fmt.Errorf("erro ao consumir: %w", sarama.ErrUnknownTopicOrPartition), false, true},
+		{"mensagem grande", sarama.ErrMessageSizeTooLarge, false, false},
+		{"configuração", fmt.Errorf("%w: KAFKA_ACKS inválido", ErrConfiguracao), false, false},
+		{"esquema", fmt.Errorf("%w: campo removido", ErrEsquemaIncompativel), false, false},
+	}
+
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			if got := BrokerIndisponivel(caso.err); got != caso.indisponivel {
+				t.Errorf("BrokerIndisponivel = %v, esperado %v", got, caso.indisponivel)
+			}
+			if got := TopicoInexistente(caso.err); got != caso.inexistente {
+				t.Errorf("TopicoInexistente = %v, esperado %v", got, caso.inexistente)
+			}
+		})
+	}
+}
@@ -1,53 +1,225 @@
 package kafka
 
 import (
-	"encoding/json"
-	"log"
-	"os"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"concurso-go-app/internal/metricas"
+	"concurso-go-app/internal/rastreamento"
 
 	"github.com/Shopify/sarama"
 )
 
-var Producer sarama.SyncProducer
+// Nomes dos headers gravados em cada registro Kafka
+const (
+	HeaderLote          = "lote"
+	HeaderTipo          = "tipo"
+	HeaderData          = "data"
+	HeaderSchemaVersion = "schema_version"
+	HeaderContentType   = "content_type"
+	HeaderTraceID       = "trace_id"
+)
+
+// Tipos de mensagem informados no header "tipo"
+const (
+	TipoHeader   = "header"
+	TipoRegistro = "registro"
+	TipoFooter   = "footer"
+	TipoErro     = "erro"
+)
+
+// SchemaVersion é a versão do contrato das mensagens
+const SchemaVersion = "1"
+
+// Metadados identificam a mensagem sem precisar decodificar o corpo
+type Metadados struct {
+	Lote    string
+	Tipo    string
+	Data    string
+	TraceID string
+}
 
-func InitProducer() error {
-	broker := os.Getenv("KAFKA_BROKER")
-	if broker == "" {
-		broker = "localhost:9092"
+// recordHeaders converte os metadados em headers do registro (campos vazios são
+// omitidos). O contexto de trace de ctx vai junto (traceparent), para o
+// consumidor continuar o trace do produtor.
+func (m Metadados) recordHeaders(ctx context.Context, contentType string) []sarama.RecordHeader {
+	pares := [][2]string{
+		{HeaderLote, m.Lote},
+		{HeaderTipo, m.Tipo},
+		{HeaderData, m.Data},
+		{HeaderSchemaVersion, SchemaVersion},
+		{HeaderContentType, contentType},
+		{HeaderTraceID, m.TraceID},
 	}
 
-	config := sarama.NewConfig()
+	var headers []sarama.RecordHeader
+	for _, par := range pares {
+		if par[1] == "" {
+			continue
+		}
+		headers = append(headers, sarama.RecordHeader{Key: []byte(par[0]), Value: []byte(par[1])})
+	}
+
+	propagados := make(map[string]string)
+	rastreamento.Injetar(ctx, propagados)
+	for nome, valor := range propagados {
+		headers = append(headers, sarama.RecordHeader{Key: []byte(nome), Value: []byte(valor)})
+	}
+	return headers
+}
+
+// particaoFixa marca mensagens que devem ir para uma partição específica
+type particaoFixa int32
+
+// particionador usa hash da chave para registros e respeita a partição
+// escolhida para mensagens de controle (header/footer)
+type particionador struct {
+	hash sarama.Partitioner
+}
+
+func novoParticionador(topic string) sarama.Partitioner {
+	return &particionador{hash: sarama.NewHashPartitioner(topic)}
+}
+
+func (p *particionador) Partition(message *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	if fixa, ok := message.Metadata.(particaoFixa); ok {
+		return int32(fixa), nil
+	}
+	return p.hash.Partition(message, numPartitions)
+}
+
+func (p *particionador) RequiresConsistency() bool {
+	return true
+}
+
+// Produtor é um produtor síncrono com client e serializador próprios. Cada
+// job cria o seu com NovoProdutor e o fecha no fim, para jobs concorrentes não
+// compartilharem conexão nem serializador.
+type Produtor struct {
+	producer     sarama.SyncProducer
+	client       sarama.Client
+	serializador Serializador
+	fechar       sync.Once
+}
+
+func NovoProdutor() (*Produtor, error) {
+	serializador, err := NovoSerializador()
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := NovaConfig()
+	if err != nil {
+		return nil, err
+	}
 	config.Producer.Return.Successes = true
 	config.Producer.RequiredAcks = sarama.WaitForAll
 	config.Producer.Retry.Max = 5
+	config.Producer.Partitioner = novoParticionador
 
-	var err error
-	Producer, err = sarama.NewSyncProducer([]string{broker}, config)
+	client, err := sarama.NewClient(Brokers(), config)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, err
 	}
 
-	log.Println("Produtor Kafka inicializado com sucesso")
-	return nil
+	p := &Produtor{producer: producer, client: client, serializador: serializador}
+	abertos.registrar(p)
+	slog.Debug("produtor Kafka inicializado")
+	return p, nil
 }
 
-func SendMessage(topic string, message interface{}) error {
-	jsonData, err := json.Marshal(message)
+// SendMessage envia uma mensagem com a chave informada (vazia = sem chave) e os
+// metadados como headers, e retorna a partição onde ela foi gravada. O envio
+// é síncrono: o contexto é verificado antes de cada mensagem.
+func (p *Produtor) SendMessage(ctx context.Context, topic string, key string, meta Metadados, message interface{}) (int32, error) {
+	dados, err := p.serializador.Serializar(meta.Tipo, message)
+	if err != nil {
+		return 0, err
+	}
+	return p.SendRawMessage(ctx, topic, key, meta, dados)
+}
+
+// SendRawMessage envia bytes já prontos, sem passar pelo serializador (usado
+// na injeção de falhas para publicar payloads malformados)
+func (p *Produtor) SendRawMessage(ctx context.Context, topic string, key string, meta Metadados, dados []byte) (int32, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	msg := &sarama.ProducerMessage{
+		Topic:   topic,
+		Value:   sarama.ByteEncoder(dados),
+		Headers: meta.recordHeaders(ctx, p.serializador.ContentType()),
+	}
+	if key != "" {
+		msg.Key = sarama.StringEncoder(key)
+	}
+
+	partition, _, err := p.enviar(msg, meta.Tipo)
+	return partition, err
+}
+
+// SendMessageToPartition envia uma mensagem diretamente para uma partição
+func (p *Produtor) SendMessageToPartition(ctx context.Context, topic string, partition int32, meta Metadados, message interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	dados, err := p.serializador.Serializar(meta.Tipo, message)
 	if err != nil {
 		return err
 	}
 
 	msg := &sarama.ProducerMessage{
-		Topic: topic,
-		Value: sarama.StringEncoder(jsonData),
+		Topic:    topic,
+		Value:    sarama.ByteEncoder(dados),
+		Headers:  meta.recordHeaders(ctx, p.serializador.ContentType()),
+		Metadata: particaoFixa(partition),
+	}
+
+	_, _, err = p.enviar(msg, meta.Tipo)
+	return err
+}
+
+// enviar publica a mensagem e registra a latência do envio por tipo
+func (p *Produtor) enviar(msg *sarama.ProducerMessage, tipo string) (int32, int64, error) {
+	inicio := time.Now()
+	partition, offset, err := p.producer.SendMessage(msg)
+	if err == nil {
+		metricas.LatenciaEnvioKafka.WithLabelValues(tipo).Observe(time.Since(inicio).Seconds())
 	}
+	return partition, offset, err
+}
 
-	_, _, err = Producer.SendMessage(msg)
+// Partitions retorna as partições do tópico conhecidas pelo produtor
+func (p *Produtor) Partitions(topic string) ([]int32, error) {
+	return p.client.Partitions(topic)
+}
+
+// Close espera as mensagens pendentes e fecha o client; pode ser chamado mais de uma vez
+func (p *Produtor) Close() error {
+	var err error
+	p.fechar.Do(func() {
+		abertos.remover(p)
+		err = p.producer.Close()
+		if errClient := p.client.Close(); err == nil {
+			err = errClient
+		}
+	})
 	return err
 }
 
+// CloseProducer fecha todos os produtores ainda abertos (encerramento do servidor)
 func CloseProducer() {
-	if Producer != nil {
-		Producer.Close()
+	for _, p := range abertos.produtores() {
+		p.Close()
 	}
 }
@@ -0,0 +1,218 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"concurso-go-app/internal/models"
+)
+
+// codificadorProtobuf gera um esquema proto3 a partir do esquema da mensagem
+// e codifica no wire format do Protobuf. Cada campo usa o seu Numero fixo;
+// long vira sint64 e json vira string.
+type codificadorProtobuf struct{}
+
+// Wire types do Protobuf
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+var (
+	protoMessage = regexp.MustCompile(`^message\s+(\w+)\s*\{`)
+	protoCampo   = regexp.MustCompile(`^(optional\s+)?(string|sint64)\s+(\w+)\s*=\s*(\d+)\s*;\s*(//\s*json)?$`)
+)
+
+func (codificadorProtobuf) tipoEsquema() string {
+	return TipoEsquemaProtobuf
+}
+
+func (codificadorProtobuf) textoEsquema(esquema models.Esquema) string {
+	var texto strings.Builder
+	texto.WriteString("syntax = \"proto3\";\n")
+	fmt.Fprintf(&texto, "package %s;\n\n", models.NamespaceEsquemas)
+	fmt.Fprintf(&texto, "message %s {\n", esquema.Nome)
+
+	for _, campo := range esquema.Campos {
+		texto.WriteString("  ")
+		if campo.Opcional {
+			texto.WriteString("optional ")
+		}
+		switch campo.Tipo {
+		case models.CampoLong:
+			fmt.Fprintf(&texto, "sint64 %s = %d;\n", campo.Nome, campo.Numero)
+		case models.CampoJSON:
+			fmt.Fprintf(&texto, "string %s = %d; // json\n", campo.Nome, campo.Numero)
+		default:
+			fmt.Fprintf(&texto, "string %s = %d;\n", campo.Nome, campo.Numero)
+		}
+	}
+
+	texto.WriteString("}\n")
+	return texto.String()
+}
+
+func (codificadorProtobuf) lerEsquema(texto string) (models.Esquema, error) {
+	var esquema models.Esquema
+	numeros := make(map[int]string)
+
+	for _, linha := range strings.Split(texto, "\n") {
+		linha = strings.TrimSpace(linha)
+		if m := protoMessage.FindStringSubmatch(linha); m != nil {
+			if esquema.Nome != "" {
+				return models.Esquema{}, fmt.Errorf("esquema protobuf com mais de uma message")
+			}
+			esquema.Nome = m[1]
+			continue
+		}
+
+		m := protoCampo.FindStringSubmatch(linha)
+		if m == nil {
+			continue
+		}
+
+		numero, _ := strconv.Atoi(m[4])
+		if numero < 1 {
+			return models.Esquema{}, fmt.Errorf("número inválido no campo %s: %d", m[3], numero)
+		}
+		if outro, usado := numeros[numero]; usado {
+			return models.Esquema{}, fmt.Errorf("número %d usado pelos campos %s e %s", numero, outro, m[3])
+		}
+		numeros[numero] = m[3]
+		campo := models.Campo{Nome: m[3], Tipo: models.CampoString, Opcional: m[1] != "", Numero: numero}
+		if m[2] == "sint64" {
+			campo.Tipo = models.CampoLong
+		} else if m[5] != "" {
+			campo.Tipo = models.CampoJSON
+		}
+		esquema.Campos = append(esquema.Campos, campo)
+	}
+
+	if esquema.Nome == "" {
+		return models.Esquema{}, fmt.Errorf("esquema protobuf sem message")
+	}
+	return esquema, nil
+}
+
+func (codificadorProtobuf) codificar(esquema models.Esquema, valores map[string]interface{}) ([]byte, error) {
+	// Índices da message no esquema: [0] é gravado como um único byte 0
+	dados := []byte{0}
+
+	for _, campo := range esquema.Campos {
+		numero := uint64(campo.Numero)
+		switch v := valores[campo.Nome].(type) {
+		case nil:
+			continue
+		case string:
+			dados = binary.AppendUvarint(dados, numero<<3|protoBytes)
+			dados = binary.AppendUvarint(dados, uint64(len(v)))
+			dados = append(dados, v...)
+		case int64:
+			dados = binary.AppendUvarint(dados, numero<<3|protoVarint)
+			dados = binary.AppendVarint(dados, v)
+		default:
+			return nil, fmt.Errorf("valor inválido no campo %s", campo.Nome)
+		}
+	}
+	return dados, nil
+}
+
+func (codificadorProtobuf) decodificar(esquema models.Esquema, dados []byte) (map[string]interface{}, error) {
+	lerUvarint := func() (uint64, error) {
+		valor, n := binary.Uvarint(dados)
+		if n <= 0 {
+			return 0, fmt.Errorf("varint inválido")
+		}
+		dados = dados[n:]
+		return valor, nil
+	}
+
+	// Pular os índices da message
+	quantidade, n := binary.Varint(dados)
+	if n <= 0 {
+		return nil, fmt.Errorf("índices da message inválidos")
+	}
+	dados = dados[n:]
+	for i := int64(0); i < quantidade; i++ {
+		_, n := binary.Varint(dados)
+		if n <= 0 {
+			return nil, fmt.Errorf("índices da message inválidos")
+		}
+		dados = dados[n:]
+	}
+
+	porNumero := make(map[int]*models.Campo, len(esquema.Campos))
+	for i := range esquema.Campos {
+		porNumero[esquema.Campos[i].Numero] = &esquema.Campos[i]
+	}
+
+	valores := make(map[string]interface{}, len(esquema.Campos))
+	for len(dados) > 0 {
+		tag, err := lerUvarint()
+		if err != nil {
+			return nil, err
+		}
+		numero, wireType := int(tag>>3), tag&7
+
+		campo := porNumero[numero] // nil: campo desconhecido, ignorado
+
+		switch wireType {
+		case protoVarint:
+			valor, n := binary.Varint(dados)
+			if n <= 0 {
+				return nil, fmt.Errorf("varint inválido")
+			}
+			dados = dados[n:]
+			if campo != nil {
+				valores[campo.Nome] = valor
+			}
+		case protoBytes:
+			tamanho, err := lerUvarint()
+			if err != nil {
+				return nil, err
+			}
+			if tamanho > uint64(len(dados)) {
+				return nil, fmt.Errorf("tamanho inválido no campo %d", numero)
+			}
+			if campo != nil {
+				valores[campo.Nome] = valorDecodificado(*campo, string(dados[:tamanho]))
+			}
+			dados = dados[tamanho:]
+		case protoFixed64, protoFixed32:
+			// Campos desconhecidos de tamanho fixo são ignorados
+			tamanho := 8
+			if wireType == protoFixed32 {
+				tamanho = 4
+			}
+			if len(dados) < tamanho {
+				return nil, fmt.Errorf("campo %d truncado", numero)
+			}
+			dados = dados[tamanho:]
+		default:
+			return nil, fmt.Errorf("wire type %d não suportado", wireType)
+		}
+	}
+
+	// Em proto3 campos sem presença explícita ausentes valem o valor padrão
+	for _, campo := range esquema.Campos {
+		if _, ok := valores[campo.Nome]; ok {
+			continue
+		}
+		switch {
+		case campo.Opcional:
+			valores[campo.Nome] = nil
+		case campo.Tipo == models.CampoLong:
+			valores[campo.Nome] = int64(0)
+		case campo.Tipo == models.CampoJSON:
+			valores[campo.Nome] = nil
+		default:
+			valores[campo.Nome] = ""
+		}
+	}
+	return valores, nil
+}
@@ -0,0 +1,245 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tipos de esquema do schema registry (schemaType)
+const (
+	TipoEsquemaAvro     = "AVRO"
+	TipoEsquemaProtobuf = "PROTOBUF"
+)
+
+// EsquemaRegistrado é um esquema como armazenado no schema registry
+type EsquemaRegistrado struct {
+	Tipo  string `json:"schemaType,omitempty"`
+	Texto string `json:"schema"`
+}
+
+// tipo retorna o schemaType, que no registry é omitido para Avro
+func (e EsquemaRegistrado) tipo() string {
+	if e.Tipo == "" {
+		return TipoEsquemaAvro
+	}
+	return e.Tipo
+}
+
+// RegistroEsquemas registra e busca esquemas (API compatível com o Confluent Schema Registry)
+type RegistroEsquemas interface {
+	Registrar(subject string, esquema EsquemaRegistrado) (int, error)
+	BuscarPorID(id int) (EsquemaRegistrado, error)
+}
+
+var (
+	registroEsquemas     RegistroEsquemas
+	registroEsquemasOnce sync.Once
+	registroEsquemasErro error
+)
+
+// RegistroEmMemoria em SCHEMA_REGISTRY_URL liga o registry dentro do
+// processo. Só para desenvolvimento: os IDs recomeçam a cada início e não são
+// compartilhados entre instâncias, então mensagens já gravadas no Kafka deixam
+// de ser lidas (ou são lidas com o esquema errado).
+const RegistroEmMemoria = "memoria"
+
+// obterRegistroEsquemas retorna o registry do processo, criado na primeira
+// chamada por novoRegistroConfigurado
+func obterRegistroEsquemas() (RegistroEsquemas, error) {
+	registroEsquemasOnce.Do(func() {
+		registroEsquemas, registroEsquemasErro = novoRegistroConfigurado()
+	})
+	return registroEsquemas, registroEsquemasErro
+}
+
+// novoRegistroConfigurado usa o registry de SCHEMA_REGISTRY_URL; sem ele não
+// há como avro e protobuf funcionarem entre reinícios e falha com ErrConfiguracao
+func novoRegistroConfigurado() (RegistroEsquemas, error) {
+	endereco := os.Getenv("SCHEMA_REGISTRY_URL")
+	switch endereco {
+	case "":
+		return nil, fmt.Errorf("%w: KAFKA_SERIALIZER=%s exige SCHEMA_REGISTRY_URL (ou %q, só para desenvolvimento)", ErrConfiguracao, os.Getenv("KAFKA_SERIALIZER"), RegistroEmMemoria)
+	case RegistroEmMemoria:
+		slog.Warn("usando schema registry em memória: os IDs dos esquemas não sobrevivem a um reinício")
+		return NovoRegistroMemoria(), nil
+	}
+	return NovoRegistroHTTP(endereco, os.Getenv("SCHEMA_REGISTRY_USER"), os.Getenv("SCHEMA_REGISTRY_PASSWORD"))
+}
+
+// RegistroMemoria é um schema registry dentro do processo, para uso local e testes
+type RegistroMemoria struct {
+	mu       sync.Mutex
+	esquemas []EsquemaRegistrado // ID = posição + 1
+	subjects map[string][]int    // IDs das versões de cada subject
+}
+
+func NovoRegistroMemoria() *RegistroMemoria {
+	return &RegistroMemoria{subjects: make(map[string][]int)}
+}
+
+func (r *RegistroMemoria) Registrar(subject string, esquema EsquemaRegistrado) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versoes := r.subjects[subject]
+	for _, id := range versoes {
+		if r.esquemas[id-1] == esquema {
+			return id, nil
+		}
+	}
+
+	// Nova versão precisa ser compatível com todas as registradas: assim o
+	// número de um campo removido numa versão não volta na seguinte
+	for versao, id := range versoes {
+		if err := compatibilidadeRegistrados(r.esquemas[id-1], esquema); err != nil {
+			return 0, fmt.Errorf("%w com a versão %d de %s: %v", ErrEsquemaIncompativel, versao+1, subject, err)
+		}
+	}
+
+	r.esquemas = append(r.esquemas, esquema)
+	id := len(r.esquemas)
+	r.subjects[subject] = append(versoes, id)
+	return id, nil
+}
+
+func (r *RegistroMemoria) BuscarPorID(id int) (EsquemaRegistrado, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.esquemas) {
+		return EsquemaRegistrado{}, fmt.Errorf("esquema %d não encontrado", id)
+	}
+	return r.esquemas[id-1], nil
+}
+
+func compatibilidadeRegistrados(anterior EsquemaRegistrado, novo EsquemaRegistrado) error {
+	if anterior.tipo() != novo.tipo() {
+		return fmt.Errorf("tipo mudou de %s para %s", anterior.tipo(), novo.tipo())
+	}
+
+	c, ok := codificadores[novo.tipo()]
+	if !ok {
+		return fmt.Errorf("tipo de esquema não suportado: %s", novo.tipo())
+	}
+
+	esquemaAnterior, err := c.lerEsquema(anterior.Texto)
+	if err != nil {
+		return err
+	}
+	esquemaNovo, err := c.lerEsquema(novo.Texto)
+	if err != nil {
+		return err
+	}
+	return verificarCompatibilidade(esquemaAnterior, esquemaNovo)
+}
+
+// RegistroHTTP acessa um schema registry compatível com o Confluent via REST
+type RegistroHTTP struct {
+	url     string
+	usuario string
+	senha   string
+	client  *http.Client
+
+	mu    sync.Mutex
+	cache map[int]EsquemaRegistrado
+}
+
+func NovoRegistroHTTP(endereco string, usuario string, senha string) (*RegistroHTTP, error) {
+	if _, err := url.ParseRequestURI(endereco); err != nil {
+		return nil, fmt.Errorf("%w: SCHEMA_REGISTRY_URL inválida: %v", ErrConfiguracao, err)
+	}
+
+	return &RegistroHTTP{
+		url:     strings.TrimRight(endereco, "/"),
+		usuario: usuario,
+		senha:   senha,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		cache:   make(map[int]EsquemaRegistrado),
+	}, nil
+}
+
+func (r *RegistroHTTP) Registrar(subject string, esquema EsquemaRegistrado) (int, error) {
+	// Avro é o padrão do registry e não leva schemaType
+	if esquema.Tipo == TipoEsquemaAvro {
+		esquema.Tipo = ""
+	}
+
+	corpo, err := json.Marshal(esquema)
+	if err != nil {
+		return 0, err
+	}
+
+	var resposta struct {
+		ID int `json:"id"`
+	}
+	caminho := fmt.Sprintf("/subjects/%s/versions", url.PathEscape(subject))
+	if err := r.requisicao(http.MethodPost, caminho, corpo, &resposta); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	r.cache[resposta.ID] = esquema
+	r.mu.Unlock()
+	return resposta.ID, nil
+}
+
+func (r *RegistroHTTP) BuscarPorID(id int) (EsquemaRegistrado, error) {
+	r.mu.Lock()
+	esquema, ok := r.cache[id]
+	r.mu.Unlock()
+	if ok {
+		return esquema, nil
+	}
+
+	if err := r.requisicao(http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil, &esquema); err != nil {
+		return EsquemaRegistrado{}, err
+	}
+
+	r.mu.Lock()
+	r.cache[id] = esquema
+	r.mu.Unlock()
+	return esquema, nil
+}
+
+func (r *RegistroHTTP) requisicao(metodo string, caminho string, corpo []byte, resposta interface{}) error {
+	req, err := http.NewRequest(metodo, r.url+caminho, bytes.NewReader(corpo))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if corpo != nil {
+		req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	}
+	if r.usuario != "" {
+		req.SetBasicAuth(r.usuario, r.senha)
+	}
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		var erro struct {
+			ErrorCode int    `json:"error_code"`
+			Message   string `json:"message"`
+		}
+		json.NewDecoder(res.Body).Decode(&erro)
+		// 409 é a resposta do registry para esquema que quebra a compatibilidade
+		if res.StatusCode == http.StatusConflict {
+			return fmt.Errorf("%w: schema registry respondeu %d: %s", ErrEsquemaIncompativel, res.StatusCode, erro.Message)
+		}
+		return fmt.Errorf("schema registry respondeu %d: %s", res.StatusCode, erro.Message)
+	}
+
+	return json.NewDecoder(res.Body).Decode(resposta)
+}
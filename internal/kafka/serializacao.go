@@ -0,0 +1,328 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"concurso-go-app/internal/models"
+)
+
+// Formatos de serialização aceitos em KAFKA_SERIALIZER
+const (
+	FormatoJSON     = "json"
+	FormatoAvro     = "avro"
+	FormatoProtobuf = "protobuf"
+)
+
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeAvro     = "application/vnd.apache.avro+binary"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// magicByte inicia as mensagens no wire format do schema registry:
+// [0][id do esquema, 4 bytes big-endian][corpo]
+const magicByte = 0
+
+// esquemasPorTipo liga o header "tipo" da mensagem ao esquema do seu corpo
+var esquemasPorTipo = map[string]models.Esquema{
+	TipoHeader:   models.EsquemaKafkaHeader,
+	TipoRegistro: models.EsquemaConcursoMensagem,
+	TipoFooter:   models.EsquemaKafkaFooter,
+	TipoErro:     models.EsquemaErroKafkaLog,
+}
+
+// Serializador converte o corpo das mensagens antes do envio
+type Serializador interface {
+	ContentType() string
+	Serializar(tipo string, message interface{}) ([]byte, error)
+}
+
+// NovoSerializador cria o serializador configurado em KAFKA_SERIALIZER (padrão
+// json). Avro e protobuf já conferem aqui o schema registry configurado.
+func NovoSerializador() (Serializador, error) {
+	formato := strings.ToLower(os.Getenv("KAFKA_SERIALIZER"))
+	var c codificador
+	var contentType string
+	switch formato {
+	case "", FormatoJSON:
+		return serializadorJSON{}, nil
+	case FormatoAvro:
+		c, contentType = codificadorAvro{}, ContentTypeAvro
+	case FormatoProtobuf:
+		c, contentType = codificadorProtobuf{}, ContentTypeProtobuf
+	default:
+		return nil, fmt.Errorf("%w: serializador desconhecido: %s", ErrConfiguracao, formato)
+	}
+	if _, err := obterRegistroEsquemas(); err != nil {
+		return nil, err
+	}
+	return novoSerializadorRegistro(c, contentType), nil
+}
+
+// serializadorJSON mantém o formato original: JSON puro, sem esquema
+type serializadorJSON struct{}
+
+func (serializadorJSON) ContentType() string {
+	return ContentTypeJSON
+}
+
+func (serializadorJSON) Serializar(tipo string, message interface{}) ([]byte, error) {
+	return json.Marshal(message)
+}
+
+// codificador implementa um formato binário baseado em esquema
+type codificador interface {
+	tipoEsquema() string
+	textoEsquema(esquema models.Esquema) string
+	lerEsquema(texto string) (models.Esquema, error)
+	codificar(esquema models.Esquema, valores map[string]interface{}) ([]byte, error)
+	decodificar(esquema models.Esquema, dados []byte) (map[string]interface{}, error)
+}
+
+// codificadores disponíveis para decodificação, pelo schemaType do registry
+var codificadores = map[string]codificador{
+	TipoEsquemaAvro:     codificadorAvro{},
+	TipoEsquemaProtobuf: codificadorProtobuf{},
+}
+
+// serializadorRegistro registra o esquema no schema registry e grava o ID
+// do esquema no início de cada mensagem
+type serializadorRegistro struct {
+	codificador codificador
+	contentType string
+
+	mu  sync.Mutex
+	ids map[string]int // subject -> id do esquema
+}
+
+func novoSerializadorRegistro(c codificador, contentType string) *serializadorRegistro {
+	return &serializadorRegistro{
+		codificador: c,
+		contentType: contentType,
+		ids:         make(map[string]int),
+	}
+}
+
+func (s *serializadorRegistro) ContentType() string {
+	return s.contentType
+}
+
+func (s *serializadorRegistro) Serializar(tipo string, message interface{}) ([]byte, error) {
+	esquema, ok := esquemasPorTipo[tipo]
+	if !ok {
+		return nil, fmt.Errorf("nenhum esquema para mensagens do tipo %q", tipo)
+	}
+
+	id, err := s.idEsquema(esquema)
+	if err != nil {
+		return nil, err
+	}
+
+	valores, err := valoresMensagem(esquema, message)
+	if err != nil {
+		return nil, err
+	}
+
+	corpo, err := s.codificador.codificar(esquema, valores)
+	if err != nil {
+		return nil, err
+	}
+
+	dados := make([]byte, 5, 5+len(corpo))
+	dados[0] = magicByte
+	binary.BigEndian.PutUint32(dados[1:5], uint32(id))
+	return append(dados, corpo...), nil
+}
+
+// idEsquema registra o esquema (uma vez por subject) e retorna seu ID
+func (s *serializadorRegistro) idEsquema(esquema models.Esquema) (int, error) {
+	// RecordNameStrategy: um subject por tipo de mensagem, independente do tópico
+	subject := models.NamespaceEsquemas + "." + esquema.Nome
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id, ok := s.ids[subject]; ok {
+		return id, nil
+	}
+
+	registro, err := obterRegistroEsquemas()
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := registro.Registrar(subject, EsquemaRegistrado{
+		Tipo:  s.codificador.tipoEsquema(),
+		Texto: s.codificador.textoEsquema(esquema),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("erro ao registrar esquema %s: %w", subject, err)
+	}
+
+	s.ids[subject] = id
+	return id, nil
+}
+
+// esquemasLidos guarda os esquemas já buscados no registry, por ID
+var esquemasLidos sync.Map
+
+// Desserializar decodifica o corpo de uma mensagem em destino. Mensagens com
+// magic byte são decodificadas pelo esquema do ID gravado nelas; as demais
+// são tratadas como JSON puro.
+func Desserializar(valor []byte, destino interface{}) error {
+	if len(valor) < 5 || valor[0] != magicByte {
+		return json.Unmarshal(valor, destino)
+	}
+
+	id := int(binary.BigEndian.Uint32(valor[1:5]))
+	c, esquema, err := esquemaPorID(id)
+	if err != nil {
+		return err
+	}
+
+	valores, err := c.decodificar(esquema, valor[5:])
+	if err != nil {
+		return fmt.Errorf("erro ao decodificar mensagem (esquema %d): %v", id, err)
+	}
+
+	// Os valores passam por JSON para reaproveitar as tags e os formatos dos modelos
+	jsonData, err := json.Marshal(valores)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonData, destino)
+}
+
+func esquemaPorID(id int) (codificador, models.Esquema, error) {
+	type esquemaLido struct {
+		codificador codificador
+		esquema     models.Esquema
+	}
+	if lido, ok := esquemasLidos.Load(id); ok {
+		return lido.(esquemaLido).codificador, lido.(esquemaLido).esquema, nil
+	}
+
+	registro, err := obterRegistroEsquemas()
+	if err != nil {
+		return nil, models.Esquema{}, err
+	}
+
+	registrado, err := registro.BuscarPorID(id)
+	if err != nil {
+		return nil, models.Esquema{}, fmt.Errorf("erro ao buscar esquema %d: %v", id, err)
+	}
+
+	c, ok := codificadores[registrado.tipo()]
+	if !ok {
+		return nil, models.Esquema{}, fmt.Errorf("tipo de esquema não suportado: %s", registrado.tipo())
+	}
+
+	esquema, err := c.lerEsquema(registrado.Texto)
+	if err != nil {
+		return nil, models.Esquema{}, fmt.Errorf("erro ao ler esquema %d: %v", id, err)
+	}
+
+	esquemasLidos.Store(id, esquemaLido{codificador: c, esquema: esquema})
+	return c, esquema, nil
+}
+
+// valoresMensagem extrai da mensagem (via JSON) os valores dos campos do esquema
+func valoresMensagem(esquema models.Esquema, message interface{}) (map[string]interface{}, error) {
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	var campos map[string]interface{}
+	if err := decoder.Decode(&campos); err != nil {
+		return nil, err
+	}
+
+	valores := make(map[string]interface{}, len(esquema.Campos))
+	for _, campo := range esquema.Campos {
+		valor := campos[campo.Nome]
+		if valor == nil {
+			if !campo.Opcional {
+				return nil, fmt.Errorf("campo obrigatório %s ausente em %s", campo.Nome, esquema.Nome)
+			}
+			valores[campo.Nome] = nil
+			continue
+		}
+
+		switch campo.Tipo {
+		case models.CampoString:
+			texto, ok := valor.(string)
+			if !ok {
+				return nil, fmt.Errorf("campo %s deveria ser string", campo.Nome)
+			}
+			valores[campo.Nome] = texto
+		case models.CampoLong:
+			numero, ok := valor.(json.Number)
+			if !ok {
+				return nil, fmt.Errorf("campo %s deveria ser numérico", campo.Nome)
+			}
+			inteiro, err := numero.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("campo %s deveria ser inteiro: %v", campo.Nome, err)
+			}
+			valores[campo.Nome] = inteiro
+		case models.CampoJSON:
+			texto, err := json.Marshal(valor)
+			if err != nil {
+				return nil, err
+			}
+			valores[campo.Nome] = string(texto)
+		default:
+			return nil, fmt.Errorf("tipo de campo não suportado: %s", campo.Tipo)
+		}
+	}
+	return valores, nil
+}
+
+// valorDecodificado converte o texto de um campo json de volta em JSON bruto
+func valorDecodificado(campo models.Campo, texto string) interface{} {
+	if campo.Tipo == models.CampoJSON {
+		return json.RawMessage(texto)
+	}
+	return texto
+}
+
+// verificarCompatibilidade aplica a regra BACKWARD: quem lê com o esquema novo
+// precisa conseguir ler mensagens gravadas com o anterior. No Protobuf o
+// número identifica o campo no corpo, então não pode mudar nem ser reaproveitado
+// (no Avro os números são zero e não entram na comparação).
+func verificarCompatibilidade(anterior models.Esquema, novo models.Esquema) error {
+	porNome := make(map[string]models.Campo, len(anterior.Campos))
+	porNumero := make(map[int]string, len(anterior.Campos))
+	for _, campo := range anterior.Campos {
+		porNome[campo.Nome] = campo
+		if campo.Numero > 0 {
+			porNumero[campo.Numero] = campo.Nome
+		}
+	}
+
+	for _, campo := range novo.Campos {
+		antigo, existia := porNome[campo.Nome]
+		if !existia && !campo.Opcional {
+			return fmt.Errorf("campo novo %s precisa ser opcional", campo.Nome)
+		}
+		if existia && antigo.Tipo != campo.Tipo {
+			return fmt.Errorf("campo %s mudou de %s para %s", campo.Nome, antigo.Tipo, campo.Tipo)
+		}
+		if existia && antigo.Numero != campo.Numero {
+			return fmt.Errorf("campo %s mudou do número %d para %d", campo.Nome, antigo.Numero, campo.Numero)
+		}
+		if dono, usado := porNumero[campo.Numero]; usado && dono != campo.Nome {
+			return fmt.Errorf("número %d do campo %s já foi do campo %s", campo.Numero, campo.Nome, dono)
+		}
+	}
+	return nil
+}
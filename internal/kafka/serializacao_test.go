@@ -0,0 +1,431 @@
+package kafka
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"concurso-go-app/internal/models"
+)
+
+func TestMain(m *testing.M) {
+	// Os testes usam sempre o registry em memória, mesmo com SCHEMA_REGISTRY_URL no ambiente
+	registroEsquemasOnce.Do(func() {})
+	os.Exit(m.Run())
+}
+
+// novoRegistro troca o registry do processo por um vazio: Avro e Protobuf
+// usam os mesmos subjects, então cada teste começa do zero
+func novoRegistro(t *testing.T) {
+	t.Helper()
+	registroEsquemas = NovoRegistroMemoria()
+	esquemasLidos.Range(func(id, _ interface{}) bool {
+		esquemasLidos.Delete(id)
+		return true
+	})
+}
+
+func mensagem(id int, status string) models.ConcursoMensagem {
+	return models.NovaConcursoMensagem(models.Concurso{
+		ID:        id,
+		Nome:      "Ana",
+		Status:    sql.NullString{String: status, Valid: status != ""},
+		DataProva: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+}
+
+// string no binário: tamanho em varint zigzag seguido dos bytes
+func avroString(texto string) []byte {
+	return append(binary.AppendVarint(nil, int64(len(texto))), texto...)
+}
+
+// separarWireFormat confere o magic byte e devolve o ID do esquema e o corpo
+func separarWireFormat(t *testing.T, dados []byte) (int, []byte) {
+	t.Helper()
+	if len(dados) < 5 || dados[0] != magicByte {
+		t.Fatalf("mensagem sem magic byte: %x", dados)
+	}
+	return int(binary.BigEndian.Uint32(dados[1:5])), dados[5:]
+}
+
+func TestAvroWireFormat(t *testing.T) {
+	novoRegistro(t)
+	s := novoSerializadorRegistro(codificadorAvro{}, ContentTypeAvro)
+
+	casos := []struct {
+		nome     string
+		mensagem models.ConcursoMensagem
+		corpo    []byte
+	}{
+		{
+			"status preenchido",
+			mensagem(64, "aprovado"),
+			// id 64 em zigzag = 128 = 0x80 0x01; status é a união ["null", string], índice 1
+			bytes.Join([][]byte{{0x80, 0x01}, avroString("Ana"), {0x02}, avroString("aprovado"), avroString("2025-01-01")}, nil),
+		},
+		{
+			"status nulo",
+			mensagem(1, ""),
+			bytes.Join([][]byte{{0x02}, avroString("Ana"), {0x00}, avroString("2025-01-01")}, nil),
+		},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			dados, err := s.Serializar(TipoRegistro, c.mensagem)
+			if err != nil {
+				t.Fatal(err)
+			}
+			id, corpo := separarWireFormat(t, dados)
+			if !bytes.Equal(corpo, c.corpo) {
+				t.Fatalf("corpo = %x, esperado %x", corpo, c.corpo)
+			}
+
+			registrado, err := registroEsquemas.BuscarPorID(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if registrado.tipo() != TipoEsquemaAvro || !strings.Contains(registrado.Texto, `"name":"ConcursoMensagem"`) {
+				t.Fatalf("esquema %d registrado = %+v", id, registrado)
+			}
+
+			var lida models.ConcursoMensagem
+			if err := Desserializar(dados, &lida); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(lida, c.mensagem) {
+				t.Fatalf("ida e volta = %+v, esperado %+v", lida, c.mensagem)
+			}
+		})
+	}
+}
+
+func TestProtobufWireFormat(t *testing.T) {
+	novoRegistro(t)
+	s := novoSerializadorRegistro(codificadorProtobuf{}, ContentTypeProtobuf)
+
+	casos := []struct {
+		nome     string
+		mensagem models.ConcursoMensagem
+		corpo    []byte
+	}{
+		{
+			"status preenchido",
+			mensagem(64, "aprovado"),
+			// 0x00 = índices da message ([0]); campo 1 sint64 (tag 0x08) em
+			// zigzag; campos 2, 3 e 4 length-delimited (tags 0x12, 0x1a, 0x22)
+			bytes.Join([][]byte{
+				{0x00},
+				{0x08, 0x80, 0x01},
+				{0x12, 3}, []byte("Ana"),
+				{0x1a, 8}, []byte("aprovado"),
+				{0x22, 10}, []byte("2025-01-01"),
+			}, nil),
+		},
+		{
+			"status nulo fica fora da mensagem",
+			mensagem(1, ""),
+			bytes.Join([][]byte{{0x00}, {0x08, 0x02}, {0x12, 3}, []byte("Ana"), {0x22, 10}, []byte("2025-01-01")}, nil),
+		},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			dados, err := s.Serializar(TipoRegistro, c.mensagem)
+			if err != nil {
+				t.Fatal(err)
+			}
+			id, corpo := separarWireFormat(t, dados)
+			if !bytes.Equal(corpo, c.corpo) {
+				t.Fatalf("corpo = %x, esperado %x", corpo, c.corpo)
+			}
+
+			registrado, err := registroEsquemas.BuscarPorID(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if registrado.tipo() != TipoEsquemaProtobuf || !strings.Contains(registrado.Texto, "optional string status = 3;") {
+				t.Fatalf("esquema %d registrado = %+v", id, registrado)
+			}
+
+			var lida models.ConcursoMensagem
+			if err := Desserializar(dados, &lida); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(lida, c.mensagem) {
+				t.Fatalf("ida e volta = %+v, esperado %+v", lida, c.mensagem)
+			}
+		})
+	}
+}
+
+// Produtores de outras linguagens gravam a lista de índices completa
+// ([1, 0] = primeira message); o decodificador precisa pulá-la
+func TestProtobufIndicesDaMessage(t *testing.T) {
+	corpo := binary.AppendVarint(nil, 1)
+	corpo = binary.AppendVarint(corpo, 0)
+	corpo = append(corpo, 0x08, 0x0e) // id = 7
+
+	valores, err := codificadorProtobuf{}.decodificar(models.EsquemaConcursoMensagem, corpo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperado := map[string]interface{}{"id": int64(7), "nome": "", "status": nil, "data_prova": ""}
+	if !reflect.DeepEqual(valores, esperado) {
+		t.Fatalf("valores = %v, esperado %v", valores, esperado)
+	}
+}
+
+func TestCampoJSONIdaEVolta(t *testing.T) {
+	erro := models.ErroKafkaLog{
+		IDLinhaKafka: "0-42",
+		Payload:      map[string]interface{}{"id": float64(42), "status": "pendente"},
+		Motivo:       "status inválido",
+		Timestamp:    time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	for _, s := range []Serializador{
+		novoSerializadorRegistro(codificadorAvro{}, ContentTypeAvro),
+		novoSerializadorRegistro(codificadorProtobuf{}, ContentTypeProtobuf),
+	} {
+		t.Run(s.ContentType(), func(t *testing.T) {
+			novoRegistro(t)
+			dados, err := s.Serializar(TipoErro, erro)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var lido models.ErroKafkaLog
+			if err := Desserializar(dados, &lido); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(lido, erro) {
+				t.Fatalf("ida e volta = %+v, esperado %+v", lido, erro)
+			}
+		})
+	}
+}
+
+func TestDesserializarJSONPuro(t *testing.T) {
+	dados, err := serializadorJSON{}.Serializar(TipoRegistro, mensagem(5, "reprovado"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lida models.ConcursoMensagem
+	if err := Desserializar(dados, &lida); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(lida, mensagem(5, "reprovado")) {
+		t.Fatalf("lida = %+v", lida)
+	}
+}
+
+func TestRegistroMemoriaCompatibilidadeBackward(t *testing.T) {
+	avro := codificadorAvro{}
+	versao1 := models.Esquema{Nome: "Teste", Campos: []models.Campo{
+		{Nome: "id", Tipo: models.CampoLong},
+		{Nome: "nome", Tipo: models.CampoString},
+	}}
+	registrar := func(r *RegistroMemoria, c codificador, esquema models.Esquema) (int, error) {
+		return r.Registrar("concurso.Teste", EsquemaRegistrado{Tipo: c.tipoEsquema(), Texto: c.textoEsquema(esquema)})
+	}
+	comCampos := func(campos ...models.Campo) models.Esquema {
+		return models.Esquema{Nome: "Teste", Campos: append(append([]models.Campo{}, versao1.Campos...), campos...)}
+	}
+
+	casos := []struct {
+		nome        string
+		codificador codificador
+		novo        models.Esquema
+		erro        string
+	}{
+		{"campo novo opcional", avro, comCampos(models.Campo{Nome: "lote", Tipo: models.CampoString, Opcional: true}), ""},
+		{"campo novo obrigatório", avro, comCampos(models.Campo{Nome: "lote", Tipo: models.CampoString}), "campo novo lote precisa ser opcional"},
+		{"tipo do campo mudou", avro, models.Esquema{Nome: "Teste", Campos: []models.Campo{
+			{Nome: "id", Tipo: models.CampoString},
+			{Nome: "nome", Tipo: models.CampoString},
+		}}, "campo id mudou de long para string"},
+		{"tipo do esquema mudou", codificadorProtobuf{}, versao1, "tipo mudou de AVRO para PROTOBUF"},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			r := NovoRegistroMemoria()
+			id1, err := registrar(r, avro, versao1)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			id2, err := registrar(r, c.codificador, c.novo)
+			if c.erro == "" {
+				if err != nil || id2 == id1 {
+					t.Fatalf("Registrar = %d, %v; esperado uma versão nova", id2, err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrEsquemaIncompativel) || !strings.Contains(err.Error(), c.erro) {
+				t.Fatalf("erro = %v, esperado %q", err, c.erro)
+			}
+			if _, err := r.BuscarPorID(2); err == nil {
+				t.Fatal("esquema incompatível não pode ser registrado")
+			}
+		})
+	}
+
+	// O mesmo esquema de novo devolve o ID já registrado
+	r := NovoRegistroMemoria()
+	id1, _ := registrar(r, avro, versao1)
+	if id, err := registrar(r, avro, versao1); err != nil || id != id1 {
+		t.Fatalf("registrar de novo = %d, %v; esperado %d", id, err, id1)
+	}
+}
+
+func TestSerializarSemEsquema(t *testing.T) {
+	novoRegistro(t)
+	s := novoSerializadorRegistro(codificadorAvro{}, ContentTypeAvro)
+	if _, err := s.Serializar("desconhecido", map[string]string{}); err == nil {
+		t.Fatal("esperava erro para tipo sem esquema")
+	}
+	// Campo obrigatório ausente não gera mensagem
+	if _, err := s.Serializar(TipoHeader, map[string]interface{}{"lote": "x"}); err == nil || !strings.Contains(err.Error(), "total_esperado") {
+		t.Fatalf("erro = %v", err)
+	}
+}
+
+func TestRegistroMemoriaNumerosProtobuf(t *testing.T) {
+	proto := codificadorProtobuf{}
+	esquema := func(campos ...models.Campo) EsquemaRegistrado {
+		return EsquemaRegistrado{Tipo: proto.tipoEsquema(), Texto: proto.textoEsquema(models.Esquema{Nome: "Teste", Campos: campos})}
+	}
+	id := models.Campo{Nome: "id", Tipo: models.CampoLong, Numero: 1}
+	nome := models.Campo{Nome: "nome", Tipo: models.CampoString, Numero: 2}
+	status := models.Campo{Nome: "status", Tipo: models.CampoString, Opcional: true, Numero: 3}
+
+	casos := []struct {
+		nome    string
+		versoes []EsquemaRegistrado // registradas depois da versão 1, em ordem
+		erro    string
+	}{
+		{"campo novo no meio com número novo", []EsquemaRegistrado{
+			esquema(id, models.Campo{Nome: "lote", Tipo: models.CampoString, Opcional: true, Numero: 4}, nome, status),
+		}, ""},
+		{"campo removido", []EsquemaRegistrado{esquema(id, nome)}, ""},
+		{"campo renumerado", []EsquemaRegistrado{
+			esquema(id, models.Campo{Nome: "nome", Tipo: models.CampoString, Numero: 3}, models.Campo{Nome: "status", Tipo: models.CampoString, Opcional: true, Numero: 2}),
+		}, "campo nome mudou do número 2 para 3"},
+		{"número reaproveitado", []EsquemaRegistrado{
+			esquema(id, nome, models.Campo{Nome: "lote", Tipo: models.CampoString, Opcional: true, Numero: 3}),
+		}, "número 3 do campo lote já foi do campo status"},
+		{"número de campo removido em versão anterior", []EsquemaRegistrado{
+			esquema(id, nome),
+			esquema(id, nome, models.Campo{Nome: "lote", Tipo: models.CampoString, Opcional: true, Numero: 3}),
+		}, "número 3 do campo lote já foi do campo status"},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			r := NovoRegistroMemoria()
+			if _, err := r.Registrar("concurso.Teste", esquema(id, nome, status)); err != nil {
+				t.Fatal(err)
+			}
+			var err error
+			for _, versao := range c.versoes {
+				if _, err = r.Registrar("concurso.Teste", versao); err != nil {
+					break
+				}
+			}
+			if c.erro == "" {
+				if err != nil {
+					t.Fatalf("Registrar: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrEsquemaIncompativel) || !strings.Contains(err.Error(), c.erro) {
+				t.Fatalf("erro = %v, esperado %q", err, c.erro)
+			}
+		})
+	}
+}
+
+// O texto do esquema guarda os números: quem lê com ele acha cada campo pelo
+// número, mesmo fora de ordem ou com lacunas
+func TestProtobufNumerosNoEsquema(t *testing.T) {
+	proto := codificadorProtobuf{}
+	esquema := models.Esquema{Nome: "Teste", Campos: []models.Campo{
+		{Nome: "id", Tipo: models.CampoLong, Numero: 1},
+		{Nome: "lote", Tipo: models.CampoString, Opcional: true, Numero: 5},
+		{Nome: "nome", Tipo: models.CampoString, Numero: 2},
+	}}
+
+	lido, err := proto.lerEsquema(proto.textoEsquema(esquema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(lido, esquema) {
+		t.Fatalf("esquema lido = %+v, esperado %+v", lido, esquema)
+	}
+
+	valores := map[string]interface{}{"id": int64(7), "lote": "l1", "nome": "Ana"}
+	corpo, err := proto.codificar(esquema, valores)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(corpo, []byte{5<<3 | protoBytes, 2, 'l', '1'}) {
+		t.Fatalf("campo lote fora do número 5: %x", corpo)
+	}
+	decodificados, err := proto.decodificar(lido, corpo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decodificados, valores) {
+		t.Fatalf("valores = %v, esperado %v", decodificados, valores)
+	}
+
+	if _, err := proto.lerEsquema("message Teste {\n  sint64 id = 1;\n  string nome = 1;\n}\n"); err == nil {
+		t.Fatal("esperava erro para número repetido")
+	}
+}
+
+func TestEsquemasComNumerosUnicos(t *testing.T) {
+	for tipo, esquema := range esquemasPorTipo {
+		numeros := make(map[int]string)
+		for _, campo := range esquema.Campos {
+			if campo.Numero < 1 {
+				t.Errorf("%s: campo %s sem número", tipo, campo.Nome)
+			}
+			if outro, usado := numeros[campo.Numero]; usado {
+				t.Errorf("%s: número %d usado por %s e %s", tipo, campo.Numero, outro, campo.Nome)
+			}
+			numeros[campo.Numero] = campo.Nome
+		}
+	}
+}
+
+func TestNovoRegistroConfigurado(t *testing.T) {
+	casos := []struct {
+		nome     string
+		endereco string
+		tipo     interface{}
+		erro     bool
+	}{
+		{"sem SCHEMA_REGISTRY_URL", "", nil, true},
+		{"em memória só quando pedido", RegistroEmMemoria, &RegistroMemoria{}, false},
+		{"registry HTTP", "http://registry:8081", &RegistroHTTP{}, false},
+		{"URL inválida", "registry.local", nil, true},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			t.Setenv("SCHEMA_REGISTRY_URL", c.endereco)
+			registro, err := novoRegistroConfigurado()
+			if c.erro {
+				if !errors.Is(err, ErrConfiguracao) {
+					t.Fatalf("erro = %v, esperado ErrConfiguracao", err)
+				}
+				return
+			}
+			if err != nil || reflect.TypeOf(registro) != reflect.TypeOf(c.tipo) {
+				t.Fatalf("novoRegistroConfigurado() = %T, %v; esperado %T", registro, err, c.tipo)
+			}
+		})
+	}
+}
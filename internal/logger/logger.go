@@ -0,0 +1,97 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Atributos de correlação presentes em toda linha registrada com o contexto
+const (
+	ChaveRequestID = "request_id"
+	ChavePrincipal = "principal"
+	ChaveJobID     = "job_id"
+	ChaveData      = "data"
+	ChaveLote      = "lote"
+)
+
+type chaveContexto int
+
+const chaveAtributos chaveContexto = iota
+
+// Novo cria o logger conforme LOG_LEVEL (debug, info, warn ou error; padrão
+// info) e LOG_FORMAT (text ou json; padrão text), escrevendo na saída padrão.
+// Os atributos guardados com ComAtributos entram em toda linha *Context.
+func Novo() (*slog.Logger, error) {
+	nivel := slog.LevelInfo
+	if valor := os.Getenv("LOG_LEVEL"); valor != "" {
+		if err := nivel.UnmarshalText([]byte(valor)); err != nil {
+			return nil, fmt.Errorf("LOG_LEVEL inválido: %s (use debug, info, warn ou error)", valor)
+		}
+	}
+	opcoes := &slog.HandlerOptions{Level: nivel}
+
+	var handler slog.Handler
+	switch formato := strings.ToLower(os.Getenv("LOG_FORMAT")); formato {
+	case "", "text":
+		handler = slog.NewTextHandler(os.Stdout, opcoes)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stdout, opcoes)
+	default:
+		return nil, fmt.Errorf("LOG_FORMAT inválido: %s (use text ou json)", formato)
+	}
+	return slog.New(handlerContexto{handler}), nil
+}
+
+// ComAtributos devolve ctx com atributos (request_id, job_id, data, lote...)
+// que o logger acrescenta às linhas registradas com ele. Uma chave repetida
+// substitui o valor anterior.
+func ComAtributos(ctx context.Context, atributos ...slog.Attr) context.Context {
+	anteriores := atributosDe(ctx)
+	todos := make([]slog.Attr, 0, len(anteriores)+len(atributos))
+	for _, anterior := range anteriores {
+		substituido := false
+		for _, novo := range atributos {
+			if novo.Key == anterior.Key {
+				substituido = true
+				break
+			}
+		}
+		if !substituido {
+			todos = append(todos, anterior)
+		}
+	}
+	todos = append(todos, atributos...)
+	return context.WithValue(ctx, chaveAtributos, todos)
+}
+
+func atributosDe(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	atributos, _ := ctx.Value(chaveAtributos).([]slog.Attr)
+	return atributos
+}
+
+// handlerContexto acrescenta os atributos do contexto a cada registro
+type handlerContexto struct {
+	slog.Handler
+}
+
+func (h handlerContexto) Handle(ctx context.Context, r slog.Record) error {
+	if atributos := atributosDe(ctx); len(atributos) > 0 {
+		r = r.Clone()
+		r.AddAttrs(atributos...)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h handlerContexto) WithAttrs(atributos []slog.Attr) slog.Handler {
+	return handlerContexto{h.Handler.WithAttrs(atributos)}
+}
+
+func (h handlerContexto) WithGroup(nome string) slog.Handler {
+	return handlerContexto{h.Handler.WithGroup(nome)}
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"concurso-go-app/internal/jobs"
+)
+
+// intervaloPadrao é o intervalo entre linhas de progresso sem LOG_PROGRESS_INTERVAL
+const intervaloPadrao = 5 * time.Second
+
+// intervaloEventos é o intervalo entre eventos de progresso do job (GET /jobs/{id}/eventos)
+const intervaloEventos = time.Second
+
+// Progresso registra o andamento de uma etapa longa (extração, envio,
+// consumo, inserção) no máximo uma vez por LOG_PROGRESS_INTERVAL (padrão
+// 5s; 0 desliga). Pode ser usado por várias goroutines.
+type Progresso struct {
+	log       *slog.Logger
+	mensagem  string
+	intervalo time.Duration
+
+	mu           sync.Mutex
+	ultimo       time.Time
+	ultimoEvento time.Time
+}
+
+// NovoProgresso cria o controle de progresso; mensagem descreve a etapa
+// ("registros enviados ao Kafka")
+func NovoProgresso(log *slog.Logger, mensagem string) *Progresso {
+	intervalo := intervaloPadrao
+	if valor := os.Getenv("LOG_PROGRESS_INTERVAL"); valor != "" {
+		if d, err := time.ParseDuration(valor); err == nil && d >= 0 {
+			intervalo = d
+		} else {
+			log.Warn("LOG_PROGRESS_INTERVAL inválido, usando o padrão", "valor", valor, "padrao", intervaloPadrao)
+		}
+	}
+	return &Progresso{log: log, mensagem: mensagem, intervalo: intervalo, ultimo: time.Now()}
+}
+
+// Registrar loga atual/total se o intervalo já passou desde a última linha
+// (total 0 = desconhecido). Independente do log, publica o progresso no job
+// em andamento no máximo uma vez por segundo.
+func (p *Progresso) Registrar(ctx context.Context, atual int, total int) {
+	agora := time.Now()
+	p.mu.Lock()
+	logar := p.intervalo > 0 && agora.Sub(p.ultimo) >= p.intervalo
+	if logar {
+		p.ultimo = agora
+	}
+	publicar := agora.Sub(p.ultimoEvento) >= intervaloEventos
+	if publicar {
+		p.ultimoEvento = agora
+	}
+	p.mu.Unlock()
+	if !logar && !publicar {
+		return
+	}
+
+	args := []any{"atual", atual}
+	if total > 0 {
+		args = append(args, "total", total, "percentual", atual*100/total)
+	}
+	if logar {
+		p.log.InfoContext(ctx, p.mensagem, args...)
+	}
+	if publicar {
+		jobs.Publicar(ctx, jobs.EventoProgresso, p.mensagem, args...)
+	}
+}
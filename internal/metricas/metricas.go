@@ -0,0 +1,92 @@
+package metricas
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "concurso"
+
+// Contadores de registros em cada etapa do pipeline
+var (
+	RegistrosExtraidos = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registros_extraidos_total",
+		Help:      "Registros lidos da tabela concurso para extração.",
+	})
+	RegistrosEnviados = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registros_enviados_total",
+		Help:      "Registros publicados no Kafka.",
+	})
+	RegistrosConsumidos = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registros_consumidos_total",
+		Help:      "Registros lidos do Kafka no consumo.",
+	})
+	RegistrosInseridos = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registros_inseridos_total",
+		Help:      "Registros gravados em concurso_processado, por método.",
+	}, []string{"metodo"})
+	RegistrosRejeitados = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registros_rejeitados_total",
+		Help:      "Registros de lotes rejeitados, por motivo.",
+	}, []string{"motivo"})
+)
+
+// Durações e latências
+var (
+	DuracaoFase = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "fase_duracao_segundos",
+		Help:      "Duração de cada fase do pipeline.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 10), // 10ms a ~43min
+	}, []string{"etapa", "fase"})
+	LatenciaEnvioKafka = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "kafka_envio_latencia_segundos",
+		Help:      "Latência de cada envio síncrono ao Kafka, por tipo de mensagem.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"tipo"})
+	LatenciaLoteBanco = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "banco_lote_latencia_segundos",
+		Help:      "Latência de cada INSERT multi-row, por tabela.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"tabela"})
+)
+
+// Estado dos jobs
+var (
+	JobsAtivos = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "jobs_ativos",
+		Help:      "Jobs em execução, por tipo.",
+	}, []string{"tipo"})
+	Falhas = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "falhas_total",
+		Help:      "Jobs que terminaram com erro, por tipo e categoria (BANCO, KAFKA, LOTE).",
+	}, []string{"tipo", "categoria"})
+	UltimoSucesso = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ultimo_sucesso_timestamp_segundos",
+		Help:      "Momento da última extração/consumo com sucesso de cada data.",
+	}, []string{"etapa", "data"})
+)
+
+// ObservarFase registra quanto tempo a fase levou desde inicio
+func ObservarFase(etapa string, fase string, inicio time.Time) {
+	DuracaoFase.WithLabelValues(etapa, fase).Observe(time.Since(inicio).Seconds())
+}
+
+// Handler expõe as métricas no formato do Prometheus (GET /metrics)
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,394 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"concurso-go-app/internal/database"
+)
+
+// Um diretório de migrations por banco: sql/mysql, sql/postgres e sql/sqlite.
+// Os comandos de um arquivo são separados por uma linha com o marcador
+// separador; um arquivo com um único comando não precisa dele.
+//
+//go:embed sql
+var arquivos embed.FS
+
+// nomeLock é o lock do MySQL que impede duas instâncias de migrarem juntas
+const nomeLock = "concurso_schema_migrations"
+
+// chaveLockPostgres é a chave do advisory lock equivalente no PostgreSQL
+const chaveLockPostgres = 72135001
+
+// timeoutLock é quanto tempo (segundos) esperar pelo lock de outra instância
+const timeoutLock = 60
+
+// separador divide os comandos de um arquivo de migration. O ";" não serve
+// porque pode aparecer dentro de literais e corpos de função.
+const separador = "-- separador"
+
+var nomeArquivo = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// DDL que o MySQL não aceita com IF [NOT] EXISTS; executar confere no
+// information_schema se o comando já foi aplicado antes de rodá-lo
+var (
+	adicionarColuna = regexp.MustCompile(`(?i)^ALTER\s+TABLE\s+(\w+)\s+ADD\s+COLUMN\s+(\w+)\s`)
+	removerColuna   = regexp.MustCompile(`(?i)^ALTER\s+TABLE\s+(\w+)\s+DROP\s+COLUMN\s+(\w+)\s*;?$`)
+	criarIndice     = regexp.MustCompile(`(?i)^CREATE\s+INDEX\s+(\w+)\s+ON\s+(\w+)\s`)
+	removerIndice   = regexp.MustCompile(`(?i)^DROP\s+INDEX\s+(\w+)\s+ON\s+(\w+)\s*;?$`)
+)
+
+// Migracao é uma versão do schema com o SQL para aplicar e desfazer
+type Migracao struct {
+	Versao int
+	Nome   string
+	Up     string
+	Down   string
+}
+
+// Carregar lê as migrations embutidas do banco informado, ordenadas pela versão
+func Carregar(driver string) ([]Migracao, error) {
+	diretorio := path.Join("sql", driver)
+	entradas, err := arquivos.ReadDir(diretorio)
+	if err != nil {
+		return nil, fmt.Errorf("nenhuma migration para o banco %s: %v", driver, err)
+	}
+
+	porVersao := make(map[int]*Migracao)
+	for _, entrada := range entradas {
+		partes := nomeArquivo.FindStringSubmatch(entrada.Name())
+		if partes == nil {
+			return nil, fmt.Errorf("nome de migration inválido: %s", entrada.Name())
+		}
+
+		versao, _ := strconv.Atoi(partes[1])
+		conteudo, err := arquivos.ReadFile(path.Join(diretorio, entrada.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := porVersao[versao]
+		if !ok {
+			m = &Migracao{Versao: versao, Nome: partes[2]}
+			porVersao[versao] = m
+		}
+		if partes[3] == "up" {
+			m.Up = string(conteudo)
+		} else {
+			m.Down = string(conteudo)
+		}
+	}
+
+	var lista []Migracao
+	for _, m := range porVersao {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s precisa de up e down", m.Versao, m.Nome)
+		}
+		lista = append(lista, *m)
+	}
+	sort.Slice(lista, func(i, j int) bool { return lista[i].Versao < lista[j].Versao })
+	return lista, nil
+}
+
+// UltimaVersao retorna a versão mais recente entre as migrations embutidas
+func UltimaVersao(driver string) (int, error) {
+	lista, err := Carregar(driver)
+	if err != nil {
+		return 0, err
+	}
+	if len(lista) == 0 {
+		return 0, nil
+	}
+	return lista[len(lista)-1].Versao, nil
+}
+
+// Aplicar leva o schema até a versão alvo, aplicando (up) ou desfazendo (down)
+// migrations conforme a versão atual. Use UltimaVersao para atualizar tudo.
+//
+// No PostgreSQL e no SQLite cada migration roda numa transação junto com o
+// registro em schema_migrations: ou entra inteira ou nada. No MySQL o DDL faz
+// commit implícito e uma falha no meio deixa os comandos anteriores aplicados
+// sem registro; por isso todo comando do MySQL precisa poder rodar de novo:
+// CREATE/DROP TABLE com IF [NOT] EXISTS, e ADD/DROP COLUMN e CREATE/DROP
+// INDEX são pulados quando o information_schema mostra que já foram aplicados.
+func Aplicar(db *sql.DB, driver string, alvo int) error {
+	lista, err := Carregar(driver)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao obter conexão para migrations: %v", err)
+	}
+	defer conn.Close()
+
+	// Lock da sessão: outra instância subindo ao mesmo tempo espera aqui
+	liberar, err := obterLock(ctx, conn, driver)
+	if err != nil {
+		return err
+	}
+	defer liberar()
+
+	if err := criarTabelaVersoes(ctx, conn); err != nil {
+		return err
+	}
+
+	aplicadas, err := versoesAplicadas(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	// Subir: versões pendentes até o alvo, em ordem crescente
+	for _, m := range lista {
+		if m.Versao > alvo || aplicadas[m.Versao] {
+			continue
+		}
+		slog.InfoContext(ctx, "aplicando migration", "versao", m.Versao, "nome", m.Nome)
+		err := migrar(ctx, conn, driver, func(exec executor) error {
+			if err := executar(ctx, exec, driver, m.Up); err != nil {
+				return fmt.Errorf("erro na migration %04d_%s (up): %v", m.Versao, m.Nome, err)
+			}
+			if _, err := exec.ExecContext(ctx, database.Rebind("INSERT INTO schema_migrations (versao, nome) VALUES (?, ?)"), m.Versao, m.Nome); err != nil {
+				return fmt.Errorf("erro ao registrar migration %04d: %v", m.Versao, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Descer: versões acima do alvo, em ordem decrescente
+	for i := len(lista) - 1; i >= 0; i-- {
+		m := lista[i]
+		if m.Versao <= alvo || !aplicadas[m.Versao] {
+			continue
+		}
+		slog.InfoContext(ctx, "desfazendo migration", "versao", m.Versao, "nome", m.Nome)
+		err := migrar(ctx, conn, driver, func(exec executor) error {
+			if err := executar(ctx, exec, driver, m.Down); err != nil {
+				return fmt.Errorf("erro na migration %04d_%s (down): %v", m.Versao, m.Nome, err)
+			}
+			if _, err := exec.ExecContext(ctx, database.Rebind("DELETE FROM schema_migrations WHERE versao = ?"), m.Versao); err != nil {
+				return fmt.Errorf("erro ao remover registro da migration %04d: %v", m.Versao, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// VersaoAtual retorna a maior versão aplicada (0 se nenhuma). Só lê: roda a
+// cada /readyz e não pode esperar pelo lock de quem está migrando.
+func VersaoAtual(ctx context.Context, db *sql.DB) (int, error) {
+	var versao sql.NullInt64
+	err := db.QueryRowContext(ctx, "SELECT MAX(versao) FROM schema_migrations").Scan(&versao)
+	if err != nil {
+		// Banco novo: a tabela só é criada pelo Aplicar
+		if existe, errTabela := tabelaVersoesExiste(ctx, db); errTabela == nil && !existe {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("erro ao consultar versão do schema: %v", err)
+	}
+	return int(versao.Int64), nil
+}
+
+// tabelaVersoesExiste consulta o catálogo do banco por schema_migrations
+func tabelaVersoesExiste(ctx context.Context, db *sql.DB) (bool, error) {
+	var consulta string
+	switch database.Driver {
+	case database.DriverMySQL:
+		consulta = "SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'schema_migrations'"
+	case database.DriverPostgres:
+		consulta = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = 'schema_migrations'"
+	default:
+		consulta = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'"
+	}
+	var total int
+	if err := db.QueryRowContext(ctx, consulta).Scan(&total); err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+// obterLock impede que duas instâncias migrem ao mesmo tempo. No SQLite o
+// próprio arquivo só aceita um escritor, então não há lock explícito.
+func obterLock(ctx context.Context, conn *sql.Conn, driver string) (func(), error) {
+	switch driver {
+	case database.DriverMySQL:
+		var obtido sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", nomeLock, timeoutLock).Scan(&obtido); err != nil {
+			return nil, fmt.Errorf("erro ao obter lock de migrations: %v", err)
+		}
+		if !obtido.Valid || obtido.Int64 != 1 {
+			return nil, fmt.Errorf("não foi possível obter o lock de migrations em %ds", timeoutLock)
+		}
+		return func() { conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", nomeLock) }, nil
+
+	case database.DriverPostgres:
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("SET lock_timeout = '%ds'", timeoutLock)); err != nil {
+			return nil, fmt.Errorf("erro ao configurar lock_timeout: %v", err)
+		}
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", chaveLockPostgres); err != nil {
+			return nil, fmt.Errorf("erro ao obter lock de migrations: %v", err)
+		}
+		return func() { conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", chaveLockPostgres) }, nil
+	}
+
+	return func() {}, nil
+}
+
+func criarTabelaVersoes(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			versao BIGINT PRIMARY KEY,
+			nome VARCHAR(255) NOT NULL,
+			aplicada_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("erro ao criar tabela schema_migrations: %v", err)
+	}
+	return nil
+}
+
+func versoesAplicadas(ctx context.Context, conn *sql.Conn) (map[int]bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT versao FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler schema_migrations: %v", err)
+	}
+	defer rows.Close()
+
+	aplicadas := make(map[int]bool)
+	for rows.Next() {
+		var versao int
+		if err := rows.Scan(&versao); err != nil {
+			return nil, err
+		}
+		aplicadas[versao] = true
+	}
+	return aplicadas, rows.Err()
+}
+
+// executor é o que *sql.Conn e *sql.Tx têm em comum
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// migrar roda passo numa transação da conexão no PostgreSQL e no SQLite, que
+// têm DDL transacional; no MySQL roda direto na conexão
+func migrar(ctx context.Context, conn *sql.Conn, driver string, passo func(exec executor) error) error {
+	if driver == database.DriverMySQL {
+		return passo(conn)
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir transação da migration: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := passo(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// comandos divide o script nas linhas com o marcador separador
+func comandos(script string) []string {
+	var lista []string
+	var atual strings.Builder
+	fechar := func() {
+		if comando := strings.TrimSpace(atual.String()); comando != "" {
+			lista = append(lista, comando)
+		}
+		atual.Reset()
+	}
+	for _, linha := range strings.SplitAfter(script, "\n") {
+		if strings.TrimSpace(linha) == separador {
+			fechar()
+			continue
+		}
+		atual.WriteString(linha)
+	}
+	fechar()
+	return lista
+}
+
+// executar roda cada comando do arquivo separadamente (o driver do MySQL não
+// aceita vários comandos em um Exec sem multiStatements). No MySQL pula os
+// comandos que uma execução anterior interrompida já aplicou.
+func executar(ctx context.Context, exec executor, driver string, script string) error {
+	for _, comando := range comandos(script) {
+		if driver == database.DriverMySQL {
+			aplicado, err := jaAplicado(ctx, exec, comando)
+			if err != nil {
+				return err
+			}
+			if aplicado {
+				slog.InfoContext(ctx, "comando da migration já aplicado, pulando", "comando", strings.SplitN(comando, "\n", 2)[0])
+				continue
+			}
+		}
+		if _, err := exec.ExecContext(ctx, comando); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// guarda é a consulta ao information_schema que diz se um DDL do MySQL já
+// foi aplicado: o objeto existe (ADD, CREATE) ou não existe mais (DROP)
+type guarda struct {
+	consulta string
+	tabela   string
+	objeto   string
+	criar    bool
+}
+
+// guardaMySQL reconhece ADD/DROP COLUMN e CREATE/DROP INDEX
+func guardaMySQL(comando string) (guarda, bool) {
+	const colunas = "SELECT COUNT(*) FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND COLUMN_NAME = ?"
+	const indices = "SELECT COUNT(*) FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME = ?"
+
+	if p := adicionarColuna.FindStringSubmatch(comando); p != nil {
+		return guarda{colunas, p[1], p[2], true}, true
+	}
+	if p := removerColuna.FindStringSubmatch(comando); p != nil {
+		return guarda{colunas, p[1], p[2], false}, true
+	}
+	if p := criarIndice.FindStringSubmatch(comando); p != nil {
+		return guarda{indices, p[2], p[1], true}, true
+	}
+	if p := removerIndice.FindStringSubmatch(comando); p != nil {
+		return guarda{indices, p[2], p[1], false}, true
+	}
+	return guarda{}, false
+}
+
+// jaAplicado indica se o comando do MySQL já está refletido no schema
+func jaAplicado(ctx context.Context, exec executor, comando string) (bool, error) {
+	g, ok := guardaMySQL(comando)
+	if !ok {
+		return false, nil
+	}
+	var total int
+	if err := exec.QueryRowContext(ctx, g.consulta, g.tabela, g.objeto).Scan(&total); err != nil {
+		return false, fmt.Errorf("erro ao consultar information_schema: %v", err)
+	}
+	return (total > 0) == g.criar, nil
+}
@@ -0,0 +1,229 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"concurso-go-app/internal/database"
+
+	_ "modernc.org/sqlite"
+)
+
+func abrirSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	database.Driver = database.DriverSQLite
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "teste.db")+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tabelas(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var nomes []string
+	for rows.Next() {
+		var nome string
+		if err := rows.Scan(&nome); err != nil {
+			t.Fatal(err)
+		}
+		nomes = append(nomes, nome)
+	}
+	return nomes
+}
+
+func TestVersaoAtualBancoNovo(t *testing.T) {
+	db := abrirSQLite(t)
+	versao, err := VersaoAtual(context.Background(), db)
+	if err != nil || versao != 0 {
+		t.Fatalf("VersaoAtual() = %d, %v; esperado 0", versao, err)
+	}
+	// Consultar a versão não cria a tabela
+	if criadas := tabelas(t, db); len(criadas) != 0 {
+		t.Fatalf("tabelas depois de VersaoAtual = %v", criadas)
+	}
+}
+
+func TestAplicarSobeDesceSobe(t *testing.T) {
+	db := abrirSQLite(t)
+	ultima, err := UltimaVersao(database.DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Aplicar(db, database.DriverSQLite, ultima); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	completas := tabelas(t, db)
+	if versao, _ := VersaoAtual(context.Background(), db); versao != ultima {
+		t.Fatalf("versão depois do up = %d, esperado %d", versao, ultima)
+	}
+
+	if err := Aplicar(db, database.DriverSQLite, 0); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	if versao, _ := VersaoAtual(context.Background(), db); versao != 0 {
+		t.Fatalf("versão depois do down = %d, esperado 0", versao)
+	}
+	if restantes := tabelas(t, db); !reflect.DeepEqual(restantes, []string{"schema_migrations"}) {
+		t.Fatalf("tabelas depois do down = %v", restantes)
+	}
+
+	if err := Aplicar(db, database.DriverSQLite, ultima); err != nil {
+		t.Fatalf("up de novo: %v", err)
+	}
+	if deNovo := tabelas(t, db); !reflect.DeepEqual(deNovo, completas) {
+		t.Fatalf("tabelas do segundo up = %v, primeiro = %v", deNovo, completas)
+	}
+
+	// Sem nada pendente, aplicar de novo não faz nada
+	if err := Aplicar(db, database.DriverSQLite, ultima); err != nil {
+		t.Fatalf("up repetido: %v", err)
+	}
+}
+
+func TestAplicarDesfazMigrationComErro(t *testing.T) {
+	db := abrirSQLite(t)
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := criarTabelaVersoes(ctx, conn); err != nil {
+		t.Fatal(err)
+	}
+
+	// O segundo comando falha: a tabela do primeiro e o registro da versão
+	// não podem ficar
+	script := "CREATE TABLE parcial (id INTEGER);\n\n-- separador\n\nCREATE TABLE parcial (id INTEGER);\n"
+	err = migrar(ctx, conn, database.DriverSQLite, func(exec executor) error {
+		if err := executar(ctx, exec, database.DriverSQLite, script); err != nil {
+			return err
+		}
+		_, err := exec.ExecContext(ctx, "INSERT INTO schema_migrations (versao, nome) VALUES (99, 'parcial')")
+		return err
+	})
+	if err == nil {
+		t.Fatal("esperava erro no segundo comando")
+	}
+	conn.Close()
+
+	for _, tabela := range tabelas(t, db) {
+		if tabela == "parcial" {
+			t.Fatal("tabela parcial ficou depois do rollback")
+		}
+	}
+	if versao, _ := VersaoAtual(ctx, db); versao != 0 {
+		t.Fatalf("versão registrada = %d, esperado 0", versao)
+	}
+}
+
+func TestComandos(t *testing.T) {
+	casos := []struct {
+		nome   string
+		script string
+		quer   []string
+	}{
+		{"um comando", "CREATE TABLE a (id INT);\n", []string{"CREATE TABLE a (id INT);"}},
+		{"vazio", "\n\n", nil},
+		{
+			"ponto e vírgula em literal",
+			"INSERT INTO a VALUES ('x;y');\n\n-- separador\n\nDROP TABLE b;\n",
+			[]string{"INSERT INTO a VALUES ('x;y');", "DROP TABLE b;"},
+		},
+		{
+			"corpo de função",
+			"CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql;\n  -- separador  \nSELECT 1;",
+			[]string{"CREATE FUNCTION f() RETURNS int AS $$ BEGIN RETURN 1; END; $$ LANGUAGE plpgsql;", "SELECT 1;"},
+		},
+		{"separador sobrando", "-- separador\nSELECT 1;\n-- separador\n", []string{"SELECT 1;"}},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			if got := comandos(c.script); !reflect.DeepEqual(got, c.quer) {
+				t.Fatalf("comandos() = %q, esperado %q", got, c.quer)
+			}
+		})
+	}
+}
+
+// Os três bancos precisam ter as mesmas versões, cada uma com up e down
+func TestCarregarMesmasVersoesNosBancos(t *testing.T) {
+	var referencia []int
+	for _, driver := range []string{database.DriverMySQL, database.DriverPostgres, database.DriverSQLite} {
+		lista, err := Carregar(driver)
+		if err != nil {
+			t.Fatalf("%s: %v", driver, err)
+		}
+		var versoes []int
+		for _, m := range lista {
+			versoes = append(versoes, m.Versao)
+			if len(comandos(m.Up)) == 0 || len(comandos(m.Down)) == 0 {
+				t.Errorf("%s: migration %04d_%s sem comandos", driver, m.Versao, m.Nome)
+			}
+		}
+		if referencia == nil {
+			referencia = versoes
+		} else if !reflect.DeepEqual(versoes, referencia) {
+			t.Errorf("%s: versões %v diferentes de %v", driver, versoes, referencia)
+		}
+	}
+}
+
+func TestGuardaMySQL(t *testing.T) {
+	casos := []struct {
+		comando string
+		quer    guarda
+		ok      bool
+	}{
+		{"ALTER TABLE concurso_processado ADD COLUMN lote VARCHAR(64) NULL;", guarda{tabela: "concurso_processado", objeto: "lote", criar: true}, true},
+		{"alter table t drop column c;", guarda{tabela: "t", objeto: "c"}, true},
+		{"CREATE INDEX idx_processado_lote ON concurso_processado (lote);", guarda{tabela: "concurso_processado", objeto: "idx_processado_lote", criar: true}, true},
+		{"DROP INDEX idx_concurso_data ON concurso;", guarda{tabela: "concurso", objeto: "idx_concurso_data"}, true},
+		{"CREATE TABLE IF NOT EXISTS t (id INT);", guarda{}, false},
+		{"ALTER TABLE t MODIFY COLUMN c INT;", guarda{}, false},
+	}
+	for _, c := range casos {
+		t.Run(c.comando, func(t *testing.T) {
+			g, ok := guardaMySQL(c.comando)
+			g.consulta = ""
+			if ok != c.ok || g != c.quer {
+				t.Fatalf("guardaMySQL() = %+v, %v; esperado %+v, %v", g, ok, c.quer, c.ok)
+			}
+		})
+	}
+}
+
+// Com o commit implícito do DDL, todo comando do MySQL precisa poder rodar de
+// novo depois de uma migration interrompida
+func TestComandosMySQLRepetiveis(t *testing.T) {
+	lista, err := Carregar(database.DriverMySQL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range lista {
+		for _, script := range []string{m.Up, m.Down} {
+			for _, comando := range comandos(script) {
+				if _, ok := guardaMySQL(comando); ok {
+					continue
+				}
+				maiusculo := strings.ToUpper(comando)
+				if !strings.Contains(maiusculo, "IF NOT EXISTS") && !strings.Contains(maiusculo, "IF EXISTS") {
+					t.Errorf("migration %04d_%s: comando não pode ser repetido: %s", m.Versao, m.Nome, strings.SplitN(comando, "\n", 2)[0])
+				}
+			}
+		}
+	}
+}
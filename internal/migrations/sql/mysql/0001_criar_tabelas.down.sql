@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS concurso_processado;
+
+-- separador
+
+DROP TABLE IF EXISTS concurso;
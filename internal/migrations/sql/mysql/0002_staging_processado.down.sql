@@ -0,0 +1 @@
+DROP TABLE IF EXISTS concurso_processado_staging;
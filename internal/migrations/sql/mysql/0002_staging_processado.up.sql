@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS concurso_processado_staging (
+	carga VARCHAR(64) NOT NULL,
+	nome VARCHAR(255) NOT NULL,
+	status VARCHAR(50),
+	data_prova DATE NOT NULL,
+	INDEX idx_staging_carga (carga)
+);
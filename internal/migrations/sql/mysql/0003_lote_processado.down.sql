@@ -0,0 +1,17 @@
+DROP INDEX idx_concurso_data ON concurso;
+
+-- separador
+
+DROP INDEX idx_processado_data ON concurso_processado;
+
+-- separador
+
+DROP INDEX idx_processado_lote ON concurso_processado;
+
+-- separador
+
+ALTER TABLE concurso_processado_staging DROP COLUMN lote;
+
+-- separador
+
+ALTER TABLE concurso_processado DROP COLUMN lote;
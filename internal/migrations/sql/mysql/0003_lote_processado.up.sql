@@ -0,0 +1,17 @@
+ALTER TABLE concurso_processado ADD COLUMN lote VARCHAR(64) NULL;
+
+-- separador
+
+ALTER TABLE concurso_processado_staging ADD COLUMN lote VARCHAR(64) NULL;
+
+-- separador
+
+CREATE INDEX idx_processado_lote ON concurso_processado (lote);
+
+-- separador
+
+CREATE INDEX idx_processado_data ON concurso_processado (data_prova, id);
+
+-- separador
+
+CREATE INDEX idx_concurso_data ON concurso (data_prova, id);
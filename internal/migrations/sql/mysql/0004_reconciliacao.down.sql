@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS pipeline_execucao;
+
+-- separador
+
+DROP TABLE IF EXISTS concurso_rejeitado;
+
+-- separador
+
+DROP INDEX idx_processado_concurso ON concurso_processado;
+
+-- separador
+
+ALTER TABLE concurso_processado_staging DROP COLUMN concurso_id;
+
+-- separador
+
+ALTER TABLE concurso_processado DROP COLUMN concurso_id;
@@ -0,0 +1,40 @@
+ALTER TABLE concurso_processado ADD COLUMN concurso_id INT NULL;
+
+-- separador
+
+ALTER TABLE concurso_processado_staging ADD COLUMN concurso_id INT NULL;
+
+-- separador
+
+CREATE INDEX idx_processado_concurso ON concurso_processado (concurso_id);
+
+-- separador
+
+CREATE TABLE IF NOT EXISTS concurso_rejeitado (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	data DATE NOT NULL,
+	lote VARCHAR(64) NOT NULL,
+	motivo VARCHAR(1000) NOT NULL,
+	concurso_id INT NULL,
+	nome VARCHAR(255) NOT NULL,
+	status VARCHAR(50),
+	data_prova DATE NOT NULL,
+	criado_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	INDEX idx_rejeitado_data_lote (data, lote),
+	INDEX idx_rejeitado_concurso (concurso_id)
+);
+
+-- separador
+
+CREATE TABLE IF NOT EXISTS pipeline_execucao (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	tipo VARCHAR(20) NOT NULL,
+	data DATE NOT NULL,
+	lote VARCHAR(64) NOT NULL,
+	trace_id VARCHAR(64) NOT NULL,
+	total INT NOT NULL,
+	status VARCHAR(50) NOT NULL,
+	motivo VARCHAR(1000) NOT NULL,
+	criado_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	INDEX idx_execucao_data (data, tipo)
+);
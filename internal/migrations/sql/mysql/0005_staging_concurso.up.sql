@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS concurso_staging (
+	carga VARCHAR(64) NOT NULL,
+	ordem INT NOT NULL,
+	nome VARCHAR(255) NOT NULL,
+	status VARCHAR(50),
+	data_prova DATE NOT NULL,
+	INDEX idx_concurso_staging_carga (carga, data_prova, ordem)
+);
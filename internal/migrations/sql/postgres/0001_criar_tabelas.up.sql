@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS concurso (
+	id SERIAL PRIMARY KEY,
+	nome VARCHAR(255) NOT NULL,
+	status VARCHAR(50),
+	data_prova DATE NOT NULL
+);
+
+-- separador
+
+CREATE TABLE IF NOT EXISTS concurso_processado (
+	id SERIAL PRIMARY KEY,
+	nome VARCHAR(255) NOT NULL,
+	status VARCHAR(50) NOT NULL,
+	data_prova DATE NOT NULL
+);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS concurso_processado_staging (
+	carga VARCHAR(64) NOT NULL,
+	nome VARCHAR(255) NOT NULL,
+	status VARCHAR(50),
+	data_prova DATE NOT NULL
+);
+
+-- separador
+
+CREATE INDEX IF NOT EXISTS idx_staging_carga ON concurso_processado_staging (carga);
@@ -0,0 +1,49 @@
+ALTER TABLE concurso_processado ADD COLUMN concurso_id INTEGER;
+
+-- separador
+
+ALTER TABLE concurso_processado_staging ADD COLUMN concurso_id INTEGER;
+
+-- separador
+
+CREATE INDEX IF NOT EXISTS idx_processado_concurso ON concurso_processado (concurso_id);
+
+-- separador
+
+CREATE TABLE IF NOT EXISTS concurso_rejeitado (
+	id SERIAL PRIMARY KEY,
+	data DATE NOT NULL,
+	lote VARCHAR(64) NOT NULL,
+	motivo VARCHAR(1000) NOT NULL,
+	concurso_id INTEGER,
+	nome VARCHAR(255) NOT NULL,
+	status VARCHAR(50),
+	data_prova DATE NOT NULL,
+	criado_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- separador
+
+CREATE INDEX IF NOT EXISTS idx_rejeitado_data_lote ON concurso_rejeitado (data, lote);
+
+-- separador
+
+CREATE INDEX IF NOT EXISTS idx_rejeitado_concurso ON concurso_rejeitado (concurso_id);
+
+-- separador
+
+CREATE TABLE IF NOT EXISTS pipeline_execucao (
+	id SERIAL PRIMARY KEY,
+	tipo VARCHAR(20) NOT NULL,
+	data DATE NOT NULL,
+	lote VARCHAR(64) NOT NULL,
+	trace_id VARCHAR(64) NOT NULL,
+	total INTEGER NOT NULL,
+	status VARCHAR(50) NOT NULL,
+	motivo VARCHAR(1000) NOT NULL,
+	criado_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- separador
+
+CREATE INDEX IF NOT EXISTS idx_execucao_data ON pipeline_execucao (data, tipo);
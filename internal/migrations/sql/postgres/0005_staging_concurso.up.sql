@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS concurso_staging (
+	carga VARCHAR(64) NOT NULL,
+	ordem INTEGER NOT NULL,
+	nome VARCHAR(255) NOT NULL,
+	status VARCHAR(50),
+	data_prova DATE NOT NULL
+);
+
+-- separador
+
+CREATE INDEX IF NOT EXISTS idx_concurso_staging_carga ON concurso_staging (carga, data_prova, ordem);
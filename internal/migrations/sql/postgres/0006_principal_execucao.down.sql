@@ -0,0 +1 @@
+ALTER TABLE pipeline_execucao DROP COLUMN principal;
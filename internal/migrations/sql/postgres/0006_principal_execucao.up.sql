@@ -0,0 +1 @@
+ALTER TABLE pipeline_execucao ADD COLUMN principal VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS concurso (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	nome TEXT NOT NULL,
+	status TEXT,
+	data_prova DATE NOT NULL
+);
+
+-- separador
+
+CREATE TABLE IF NOT EXISTS concurso_processado (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	nome TEXT NOT NULL,
+	status TEXT NOT NULL,
+	data_prova DATE NOT NULL
+);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS concurso_processado_staging (
+	carga TEXT NOT NULL,
+	nome TEXT NOT NULL,
+	status TEXT,
+	data_prova DATE NOT NULL
+);
+
+-- separador
+
+CREATE INDEX IF NOT EXISTS idx_staging_carga ON concurso_processado_staging (carga);
@@ -0,0 +1,17 @@
+DROP INDEX IF EXISTS idx_concurso_data;
+
+-- separador
+
+DROP INDEX IF EXISTS idx_processado_data;
+
+-- separador
+
+DROP INDEX IF EXISTS idx_processado_lote;
+
+-- separador
+
+ALTER TABLE concurso_processado_staging DROP COLUMN lote;
+
+-- separador
+
+ALTER TABLE concurso_processado DROP COLUMN lote;
@@ -0,0 +1,49 @@
+ALTER TABLE concurso_processado ADD COLUMN concurso_id INTEGER;
+
+-- separador
+
+ALTER TABLE concurso_processado_staging ADD COLUMN concurso_id INTEGER;
+
+-- separador
+
+CREATE INDEX IF NOT EXISTS idx_processado_concurso ON concurso_processado (concurso_id);
+
+-- separador
+
+CREATE TABLE IF NOT EXISTS concurso_rejeitado (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	data DATE NOT NULL,
+	lote TEXT NOT NULL,
+	motivo TEXT NOT NULL,
+	concurso_id INTEGER,
+	nome TEXT NOT NULL,
+	status TEXT,
+	data_prova DATE NOT NULL,
+	criado_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- separador
+
+CREATE INDEX IF NOT EXISTS idx_rejeitado_data_lote ON concurso_rejeitado (data, lote);
+
+-- separador
+
+CREATE INDEX IF NOT EXISTS idx_rejeitado_concurso ON concurso_rejeitado (concurso_id);
+
+-- separador
+
+CREATE TABLE IF NOT EXISTS pipeline_execucao (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	tipo TEXT NOT NULL,
+	data DATE NOT NULL,
+	lote TEXT NOT NULL,
+	trace_id TEXT NOT NULL,
+	total INTEGER NOT NULL,
+	status TEXT NOT NULL,
+	motivo TEXT NOT NULL,
+	criado_em TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- separador
+
+CREATE INDEX IF NOT EXISTS idx_execucao_data ON pipeline_execucao (data, tipo);
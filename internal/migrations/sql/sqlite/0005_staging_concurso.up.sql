@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS concurso_staging (
+	carga TEXT NOT NULL,
+	ordem INTEGER NOT NULL,
+	nome TEXT NOT NULL,
+	status TEXT,
+	data_prova DATE NOT NULL
+);
+
+-- separador
+
+CREATE INDEX IF NOT EXISTS idx_concurso_staging_carga ON concurso_staging (carga, data_prova, ordem);
@@ -0,0 +1 @@
+ALTER TABLE pipeline_execucao ADD COLUMN principal TEXT NOT NULL DEFAULT '';
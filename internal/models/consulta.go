@@ -0,0 +1,70 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Campos pelos quais as consultas podem ser ordenadas (todos NOT NULL, o que
+// mantém a paginação por cursor estável em qualquer banco)
+var CamposOrdenacao = []string{"id", "nome", "data_prova"}
+
+// FiltroConsulta são os filtros de GET /concursos e /concursos-processados
+type FiltroConsulta struct {
+	Data        string // YYYY-MM-DD
+	Status      string // "null" filtra status NULL
+	PrefixoNome string
+	Lote        string // só em concurso_processado
+	Ordem       string // um de CamposOrdenacao
+	Decrescente bool
+	Limite      int
+	Cursor      *Cursor
+}
+
+// Cursor aponta para o último registro da página anterior: o valor do campo
+// de ordenação e o id (desempate)
+type Cursor struct {
+	Valor string `json:"v"`
+	ID    int    `json:"id"`
+}
+
+// Codificar gera o cursor opaco devolvido ao cliente
+func (c Cursor) Codificar() string {
+	dados, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(dados)
+}
+
+// DecodificarCursor lê o cursor recebido em ?cursor=
+func DecodificarCursor(valor string) (*Cursor, error) {
+	dados, err := base64.RawURLEncoding.DecodeString(valor)
+	if err != nil {
+		return nil, fmt.Errorf("cursor inválido")
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(dados, &cursor); err != nil {
+		return nil, fmt.Errorf("cursor inválido")
+	}
+	return &cursor, nil
+}
+
+// ConcursoProcessado é uma linha de concurso_processado com o lote de origem
+type ConcursoProcessado struct {
+	Concurso
+	Lote sql.NullString
+}
+
+// ConcursoConsulta é o item devolvido pela API de consulta: o mesmo contrato
+// das mensagens Kafka, mais o lote nos processados
+type ConcursoConsulta struct {
+	ConcursoMensagem
+	Lote string `json:"lote,omitempty"`
+}
+
+// PaginaConsulta é a resposta das consultas; ProximoCursor vazio indica a
+// última página
+type PaginaConsulta struct {
+	Dados         []interface{} `json:"dados"`
+	ProximoCursor string        `json:"proximo_cursor,omitempty"`
+}
@@ -0,0 +1,71 @@
+package models
+
+// Tipos de campo suportados nos esquemas das mensagens Kafka
+const (
+	CampoString = "string"
+	CampoLong   = "long"
+	CampoJSON   = "json" // valor arbitrário, trafega como texto JSON
+)
+
+// NamespaceEsquemas é o namespace/pacote usado nos esquemas Avro e Protobuf
+const NamespaceEsquemas = "concurso"
+
+// Campo descreve um campo de uma mensagem. O nome é o mesmo da tag JSON.
+// Numero é o número do campo no Protobuf: depois de publicado nunca muda, e o
+// de um campo removido não volta a ser usado.
+type Campo struct {
+	Nome     string
+	Tipo     string
+	Opcional bool
+	Numero   int
+}
+
+// Esquema descreve os campos de uma mensagem publicada no Kafka. A ordem dos
+// campos define a ordem no Avro; campos novos entram sempre como opcionais e
+// com um número ainda não usado.
+type Esquema struct {
+	Nome   string
+	Campos []Campo
+}
+
+var EsquemaConcursoMensagem = Esquema{
+	Nome: "ConcursoMensagem",
+	Campos: []Campo{
+		{Nome: "id", Tipo: CampoLong, Numero: 1},
+		{Nome: "nome", Tipo: CampoString, Numero: 2},
+		{Nome: "status", Tipo: CampoString, Opcional: true, Numero: 3},
+		{Nome: "data_prova", Tipo: CampoString, Numero: 4},
+	},
+}
+
+var EsquemaKafkaHeader = Esquema{
+	Nome: "KafkaHeader",
+	Campos: []Campo{
+		{Nome: "lote", Tipo: CampoString, Numero: 1},
+		{Nome: "total_esperado", Tipo: CampoLong, Numero: 2},
+		{Nome: "inicio_envio", Tipo: CampoString, Numero: 3},
+		{Nome: "particao", Tipo: CampoLong, Numero: 4},
+		{Nome: "total_particoes", Tipo: CampoLong, Numero: 5},
+	},
+}
+
+var EsquemaKafkaFooter = Esquema{
+	Nome: "KafkaFooter",
+	Campos: []Campo{
+		{Nome: "lote", Tipo: CampoString, Numero: 1},
+		{Nome: "total_processado", Tipo: CampoLong, Numero: 2},
+		{Nome: "fim_envio", Tipo: CampoString, Numero: 3},
+		{Nome: "particao", Tipo: CampoLong, Numero: 4},
+		{Nome: "total_particao", Tipo: CampoLong, Numero: 5},
+	},
+}
+
+var EsquemaErroKafkaLog = Esquema{
+	Nome: "ErroKafkaLog",
+	Campos: []Campo{
+		{Nome: "id_linha_kafka", Tipo: CampoString, Numero: 1},
+		{Nome: "payload", Tipo: CampoJSON, Opcional: true, Numero: 2},
+		{Nome: "motivo", Tipo: CampoString, Numero: 3},
+		{Nome: "timestamp", Tipo: CampoString, Numero: 4},
+	},
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Modos de geração: substituir apaga a tabela concurso antes, acrescentar mantém
+const (
+	ModoSubstituir  = "substituir"
+	ModoAcrescentar = "acrescentar"
+)
+
+// Limites do POST /start: os registros de um dia são gerados de uma vez na
+// memória, então tanto o dia quanto o total precisam de teto
+const (
+	MaxRegistrosPorDia  = 1000000
+	MaxDiasGeracao      = 366
+	MaxRegistrosGeracao = 20000000
+)
+
+// ConfigDia sobrescreve a configuração geral para uma data específica
+type ConfigDia struct {
+	RegistrosPorDia *int     `json:"registros_por_dia,omitempty"`
+	TaxaAprovado    *float64 `json:"taxa_aprovado,omitempty"`
+	TaxaNulo        *float64 `json:"taxa_nulo,omitempty"`
+	TaxaInvalido    *float64 `json:"taxa_invalido,omitempty"`
+}
+
+// ConfigGeracao é o corpo do POST /start. Campos omitidos usam o padrão de
+// ConfigGeracaoPadrao. As taxas são frações (0 a 1): nulo e inválido são
+// aplicadas sobre o total do dia; aprovado divide o restante entre aprovado
+// e reprovado.
+type ConfigGeracao struct {
+	DataInicio      string               `json:"data_inicio"`
+	DataFim         string               `json:"data_fim"`
+	RegistrosPorDia int                  `json:"registros_por_dia"`
+	TaxaAprovado    float64              `json:"taxa_aprovado"`
+	TaxaNulo        float64              `json:"taxa_nulo"`
+	TaxaInvalido    float64              `json:"taxa_invalido"`
+	Dias            map[string]ConfigDia `json:"dias,omitempty"`
+	Modo            string               `json:"modo"`
+	Semente         *int64               `json:"semente,omitempty"`
+}
+
+// ConfigGeracaoPadrao reproduz a massa histórica: janeiro de 2025, 161290
+// registros por dia com 70% aprovados, e o dia 01 com 1000 registros sendo
+// 900 aprovados e 100 com status NULL
+func ConfigGeracaoPadrao() ConfigGeracao {
+	registrosDia01, aprovadoDia01, nuloDia01 := 1000, 1.0, 0.1
+	return ConfigGeracao{
+		DataInicio:      "2025-01-01",
+		DataFim:         "2025-01-31",
+		RegistrosPorDia: 161290,
+		TaxaAprovado:    0.7,
+		Dias: map[string]ConfigDia{
+			"2025-01-01": {RegistrosPorDia: &registrosDia01, TaxaAprovado: &aprovadoDia01, TaxaNulo: &nuloDia01},
+		},
+		Modo: ModoSubstituir,
+	}
+}
+
+// Validar confere datas, taxas, modo e os limites de dias e registros
+func (c ConfigGeracao) Validar() error {
+	inicio, err := time.Parse(FormatoData, c.DataInicio)
+	if err != nil {
+		return fmt.Errorf("data_inicio inválida, use YYYY-MM-DD")
+	}
+	fim, err := time.Parse(FormatoData, c.DataFim)
+	if err != nil {
+		return fmt.Errorf("data_fim inválida, use YYYY-MM-DD")
+	}
+	if fim.Before(inicio) {
+		return fmt.Errorf("data_fim deve ser igual ou posterior a data_inicio")
+	}
+	if dias := int(fim.Sub(inicio).Hours()/24) + 1; dias > MaxDiasGeracao {
+		return fmt.Errorf("intervalo de %d dias passa do limite de %d", dias, MaxDiasGeracao)
+	}
+	if c.Modo != ModoSubstituir && c.Modo != ModoAcrescentar {
+		return fmt.Errorf("modo inválido: %q (use %s ou %s)", c.Modo, ModoSubstituir, ModoAcrescentar)
+	}
+
+	if err := validarDia("", c.RegistrosPorDia, c.TaxaAprovado, c.TaxaNulo, c.TaxaInvalido); err != nil {
+		return err
+	}
+	for data := range c.Dias {
+		if _, err := time.Parse(FormatoData, data); err != nil {
+			return fmt.Errorf("data inválida em dias: %q", data)
+		}
+		registros, aprovado, nulo, invalido := c.ParaDia(data)
+		if err := validarDia(data, registros, aprovado, nulo, invalido); err != nil {
+			return err
+		}
+	}
+
+	total := 0
+	for data := inicio; !data.After(fim); data = data.AddDate(0, 0, 1) {
+		registros, _, _, _ := c.ParaDia(data.Format(FormatoData))
+		total += registros
+	}
+	if total > MaxRegistrosGeracao {
+		return fmt.Errorf("total de %d registros passa do limite de %d", total, MaxRegistrosGeracao)
+	}
+	return nil
+}
+
+// ParaDia retorna a configuração efetiva de uma data
+func (c ConfigGeracao) ParaDia(data string) (registros int, aprovado, nulo, invalido float64) {
+	registros, aprovado, nulo, invalido = c.RegistrosPorDia, c.TaxaAprovado, c.TaxaNulo, c.TaxaInvalido
+	dia, ok := c.Dias[data]
+	if !ok {
+		return
+	}
+	if dia.RegistrosPorDia != nil {
+		registros = *dia.RegistrosPorDia
+	}
+	if dia.TaxaAprovado != nil {
+		aprovado = *dia.TaxaAprovado
+	}
+	if dia.TaxaNulo != nil {
+		nulo = *dia.TaxaNulo
+	}
+	if dia.TaxaInvalido != nil {
+		invalido = *dia.TaxaInvalido
+	}
+	return
+}
+
+func validarDia(data string, registros int, aprovado, nulo, invalido float64) error {
+	prefixo := ""
+	if data != "" {
+		prefixo = fmt.Sprintf("dias[%s].", data)
+	}
+	if registros < 0 || registros > MaxRegistrosPorDia {
+		return fmt.Errorf("%sregistros_por_dia deve estar entre 0 e %d", prefixo, MaxRegistrosPorDia)
+	}
+	for nome, taxa := range map[string]float64{"taxa_aprovado": aprovado, "taxa_nulo": nulo, "taxa_invalido": invalido} {
+		if taxa < 0 || taxa > 1 {
+			return fmt.Errorf("%s%s deve estar entre 0 e 1", prefixo, nome)
+		}
+	}
+	if nulo+invalido > 1 {
+		return fmt.Errorf("%staxa_nulo + taxa_invalido não pode passar de 1", prefixo)
+	}
+	return nil
+}
+
+// ResultadoGeracao resume o que foi realmente inserido
+type ResultadoGeracao struct {
+	Modo          string         `json:"modo"`
+	Semente       int64          `json:"semente"`
+	TotalInserido int            `json:"total_inserido"`
+	PorData       map[string]int `json:"por_data"`
+	PorStatus     map[string]int `json:"por_status"`
+	TempoExecucao string         `json:"tempo_execucao"`
+}
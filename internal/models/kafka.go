@@ -1,13 +1,20 @@
 package models
 
+// KafkaHeader é enviado para todas as partições do tópico no início do lote
 type KafkaHeader struct {
-	Lote          string `json:"lote"`
-	TotalEsperado int    `json:"total_esperado"`
-	InicioEnvio   string `json:"inicio_envio"`
+	Lote           string `json:"lote"`
+	TotalEsperado  int    `json:"total_esperado"`
+	InicioEnvio    string `json:"inicio_envio"`
+	Particao       int32  `json:"particao"`
+	TotalParticoes int    `json:"total_particoes"`
 }
 
+// KafkaFooter é enviado para todas as partições do tópico no fim do lote,
+// com o total geral e o total de registros gravados na partição
 type KafkaFooter struct {
 	Lote            string `json:"lote"`
 	TotalProcessado int    `json:"total_processado"`
 	FimEnvio        string `json:"fim_envio"`
+	Particao        int32  `json:"particao"`
+	TotalParticao   int    `json:"total_particao"`
 }
@@ -4,47 +4,67 @@ import "time"
 
 // ExtracaoLog representa o log de extração
 type ExtracaoLog struct {
-	Data          string    `json:"data"`
-	Lote          string    `json:"lote"`
-	TotalExtraido int       `json:"total_extraido"`
-	TempoExecucao string    `json:"tempo_execucao"`
-	Timestamp     time.Time `json:"timestamp"`
+	Data          string `json:"data"`
+	Lote          string `json:"lote"`
+	TraceID       string `json:"trace_id"`
+	Principal     string `json:"principal,omitempty"` // quem disparou a execução pela API
+	TotalExtraido int    `json:"total_extraido"`
+	TempoExecucao string `json:"tempo_execucao"`
+	Status        string `json:"status"` // "sucesso" ou "cancelado"
+	// FalhasInjetadas lista as falhas propositais do lote (só em testes de QA)
+	FalhasInjetadas []string  `json:"falhas_injetadas,omitempty"`
+	Timestamp       time.Time `json:"timestamp"`
 }
 
 // KafkaCargaLog representa o log de carga no Kafka
 type KafkaCargaLog struct {
-	Header     KafkaHeader `json:"header"`
-	Footer     KafkaFooter `json:"footer"`
-	TempoEnvio string      `json:"tempo_envio"`
-	Timestamp  time.Time   `json:"timestamp"`
+	Header              KafkaHeader   `json:"header"`
+	Footer              KafkaFooter   `json:"footer"`
+	Principal           string        `json:"principal,omitempty"`
+	EnviadosPorParticao map[int32]int `json:"enviados_por_particao"`
+	TempoEnvio          string        `json:"tempo_envio"`
+	Timestamp           time.Time     `json:"timestamp"`
 }
 
 // ConsumoLog representa o log de consumo
 type ConsumoLog struct {
-	Data               string    `json:"data"`
-	Lote               string    `json:"lote"`
-	TotalConsumido     int       `json:"total_consumido"`
-	TempoProcessamento string    `json:"tempo_processamento"`
-	Status             string    `json:"status"`
-	Timestamp          time.Time `json:"timestamp"`
+	Data               string       `json:"data"`
+	Lote               string       `json:"lote"`
+	TraceID            string       `json:"trace_id"`
+	Principal          string       `json:"principal,omitempty"`
+	TotalConsumido     int          `json:"total_consumido"`
+	TempoProcessamento string       `json:"tempo_processamento"`
+	Status             string       `json:"status"`
+	Insercao           *InsercaoLog `json:"insercao,omitempty"`
+	Timestamp          time.Time    `json:"timestamp"`
+}
+
+// InsercaoLog mede a gravação em concurso_processado
+type InsercaoLog struct {
+	Metodo              string  `json:"metodo"` // "carga_em_massa" ou "insert_lote"
+	TotalInserido       int     `json:"total_inserido"`
+	TempoInsercao       string  `json:"tempo_insercao"`
+	RegistrosPorSegundo float64 `json:"registros_por_segundo"`
 }
 
 // LoteErroLog representa o log de erro de lote
 type LoteErroLog struct {
-	Data               string     `json:"data"`
-	Lote               string     `json:"lote"`
-	Motivo             string     `json:"motivo"`
-	TotalRegistros     int        `json:"total_registros"`
-	RegistrosValidos   int        `json:"registros_validos"`
-	RegistrosInvalidos int        `json:"registros_invalidos"`
-	RegistrosComErro   []Concurso `json:"registros_com_erro"`
-	Timestamp          time.Time  `json:"timestamp"`
+	Data               string             `json:"data"`
+	Lote               string             `json:"lote"`
+	Motivo             string             `json:"motivo"`
+	Principal          string             `json:"principal,omitempty"`
+	TotalRegistros     int                `json:"total_registros"`
+	RegistrosValidos   int                `json:"registros_validos"`
+	RegistrosInvalidos int                `json:"registros_invalidos"`
+	RegistrosComErro   []ConcursoMensagem `json:"registros_com_erro"`
+	Timestamp          time.Time          `json:"timestamp"`
 }
 
 // ErroLog representa o log de erro geral
 type ErroLog struct {
-	Categoria  string      `json:"categoria"`   // "BANCO", "KAFKA", "VALIDACAO"
-	Mensagem   string      `json:"mensagem"`    // Mensagem customizada
+	Categoria  string      `json:"categoria"` // "BANCO", "KAFKA", "LOTE", "DESCONHECIDO"
+	Mensagem   string      `json:"mensagem"`  // Mensagem customizada
+	Principal  string      `json:"principal,omitempty"`
 	ErrorTrace string      `json:"error_trace"` // Stack trace técnico
 	LinhaErro  string      `json:"linha_erro"`  // Linha onde ocorreu
 	Payload    interface{} `json:"payload"`     // Dados que causaram erro
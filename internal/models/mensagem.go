@@ -0,0 +1,120 @@
+package models
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// FormatoData é o formato de data_prova no contrato das mensagens
+const FormatoData = "2006-01-02"
+
+// ConcursoMensagem é o contrato público de um concurso nas mensagens Kafka:
+// status como string ou null e data_prova como YYYY-MM-DD
+type ConcursoMensagem struct {
+	ID        int            `json:"id"`
+	Nome      string         `json:"nome"`
+	Status    StatusMensagem `json:"status"`
+	DataProva DataMensagem   `json:"data_prova"`
+}
+
+// NovaConcursoMensagem converte o registro do banco para o contrato da mensagem
+func NovaConcursoMensagem(c Concurso) ConcursoMensagem {
+	return ConcursoMensagem{
+		ID:        c.ID,
+		Nome:      c.Nome,
+		Status:    StatusMensagem{c.Status},
+		DataProva: DataMensagem{c.DataProva},
+	}
+}
+
+// Concurso converte a mensagem de volta para o modelo do banco
+func (m ConcursoMensagem) Concurso() Concurso {
+	return Concurso{
+		ID:        m.ID,
+		Nome:      m.Nome,
+		Status:    m.Status.NullString,
+		DataProva: m.DataProva.Time,
+	}
+}
+
+// NovasConcursoMensagens converte uma lista de registros para o contrato da mensagem
+func NovasConcursoMensagens(concursos []Concurso) []ConcursoMensagem {
+	mensagens := make([]ConcursoMensagem, len(concursos))
+	for i, c := range concursos {
+		mensagens[i] = NovaConcursoMensagem(c)
+	}
+	return mensagens
+}
+
+// StatusMensagem serializa o status como string ou null
+type StatusMensagem struct {
+	sql.NullString
+}
+
+func (s StatusMensagem) MarshalJSON() ([]byte, error) {
+	if !s.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(s.String)
+}
+
+// UnmarshalJSON aceita string ou null e também o formato legado
+// {"String":"aprovado","Valid":true} das mensagens já publicadas
+func (s *StatusMensagem) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	switch {
+	case bytes.Equal(b, []byte("null")):
+		s.NullString = sql.NullString{}
+		return nil
+	case len(b) > 0 && b[0] == '"':
+		var valor string
+		if err := json.Unmarshal(b, &valor); err != nil {
+			return err
+		}
+		s.NullString = sql.NullString{String: valor, Valid: true}
+		return nil
+	case len(b) > 0 && b[0] == '{':
+		var legado struct {
+			String string
+			Valid  bool
+		}
+		if err := json.Unmarshal(b, &legado); err != nil {
+			return err
+		}
+		s.NullString = sql.NullString{String: legado.String, Valid: legado.Valid}
+		return nil
+	}
+	return fmt.Errorf("status inválido: %s", b)
+}
+
+// DataMensagem serializa a data como YYYY-MM-DD
+type DataMensagem struct {
+	time.Time
+}
+
+func (d DataMensagem) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(FormatoData))
+}
+
+// UnmarshalJSON aceita YYYY-MM-DD e também o timestamp RFC3339 das mensagens legadas
+func (d *DataMensagem) UnmarshalJSON(b []byte) error {
+	var valor string
+	if err := json.Unmarshal(b, &valor); err != nil {
+		return fmt.Errorf("data_prova inválida: %s", b)
+	}
+
+	t, err := time.Parse(FormatoData, valor)
+	if err != nil {
+		legado, errLegado := time.Parse(time.RFC3339, valor)
+		if errLegado != nil {
+			return fmt.Errorf("data_prova inválida: %s", valor)
+		}
+		t = time.Date(legado.Year(), legado.Month(), legado.Day(), 0, 0, 0, 0, time.UTC)
+	}
+
+	d.Time = t
+	return nil
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestConcursoMensagemUnmarshalJSON(t *testing.T) {
+	dataProva := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	aprovado := ConcursoMensagem{ID: 1, Nome: "Ana", Status: StatusMensagem{sql.NullString{String: "aprovado", Valid: true}}, DataProva: DataMensagem{dataProva}}
+	nulo := ConcursoMensagem{ID: 1, Nome: "Ana", DataProva: DataMensagem{dataProva}}
+
+	casos := []struct {
+		nome     string
+		json     string
+		esperado ConcursoMensagem
+	}{
+		{"formato atual", `{"id":1,"nome":"Ana","status":"aprovado","data_prova":"2025-01-01"}`, aprovado},
+		{"status null", `{"id":1,"nome":"Ana","status":null,"data_prova":"2025-01-01"}`, nulo},
+		// Mensagens publicadas antes do contrato: sql.NullString e time.Time serializados direto
+		{"legado", `{"id":1,"nome":"Ana","status":{"String":"aprovado","Valid":true},"data_prova":"2025-01-01T00:00:00Z"}`, aprovado},
+		{"legado nulo", `{"id":1,"nome":"Ana","status":{"String":"","Valid":false},"data_prova":"2025-01-01T00:00:00Z"}`, nulo},
+		{"legado com fuso", `{"id":1,"nome":"Ana","status":{"String":"aprovado","Valid":true},"data_prova":"2025-01-01T00:00:00-03:00"}`, aprovado},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			var lida ConcursoMensagem
+			if err := json.Unmarshal([]byte(c.json), &lida); err != nil {
+				t.Fatal(err)
+			}
+			if lida != c.esperado {
+				t.Fatalf("lida = %+v, esperado %+v", lida, c.esperado)
+			}
+		})
+	}
+}
+
+func TestConcursoMensagemUnmarshalJSONInvalido(t *testing.T) {
+	for _, texto := range []string{
+		`{"id":1,"nome":"Ana","status":42,"data_prova":"2025-01-01"}`,
+		`{"id":1,"nome":"Ana","status":"aprovado","data_prova":"01/01/2025"}`,
+		`{"id":1,"nome":"Ana","status":"aprovado","data_prova":20250101}`,
+	} {
+		var lida ConcursoMensagem
+		if err := json.Unmarshal([]byte(texto), &lida); err == nil {
+			t.Errorf("esperava erro para %s", texto)
+		}
+	}
+}
+
+// O formato gravado é sempre o atual, mesmo para mensagens lidas no legado
+func TestConcursoMensagemMarshalJSON(t *testing.T) {
+	var lida ConcursoMensagem
+	if err := json.Unmarshal([]byte(`{"id":7,"nome":"Ana","status":{"String":"","Valid":false},"data_prova":"2025-01-31T00:00:00Z"}`), &lida); err != nil {
+		t.Fatal(err)
+	}
+	dados, err := json.Marshal(lida)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if esperado := `{"id":7,"nome":"Ana","status":null,"data_prova":"2025-01-31"}`; string(dados) != esperado {
+		t.Fatalf("json = %s, esperado %s", dados, esperado)
+	}
+}
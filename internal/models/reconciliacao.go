@@ -0,0 +1,46 @@
+package models
+
+// Tipos de execução registrados em pipeline_execucao
+const (
+	ExecucaoExtracao = "extracao"
+	ExecucaoConsumo  = "consumo"
+)
+
+// Alertas da reconciliação
+const (
+	AlertaNaoExtraido          = "nao_extraido"           // há registros na origem e nenhuma extração
+	AlertaExtraidoNaoConsumido = "extraido_nao_consumido" // o último lote extraído não foi consumido
+	AlertaCargaParcial         = "carga_parcial"          // consumido, mas faltam registros em concurso_processado
+	AlertaIDsExtras            = "ids_extras"             // concurso_processado tem ids que não estão na origem
+	AlertaDuplicados           = "duplicados"             // processados mais de uma vez
+)
+
+// ExecucaoPipeline é uma extração ou um consumo de um lote
+type ExecucaoPipeline struct {
+	Tipo    string `json:"tipo"`
+	Data    string `json:"data"`
+	Lote    string `json:"lote"`
+	TraceID string `json:"trace_id"`
+	Total   int    `json:"total"` // extração: total do header; consumo: registros consumidos
+	Status  string `json:"status"`
+	Motivo  string `json:"motivo,omitempty"`
+	// Principal é quem disparou a execução pela API (vazio fora dela)
+	Principal string `json:"principal,omitempty"`
+}
+
+// ReconciliacaoData compara origem e destino de uma data
+type ReconciliacaoData struct {
+	Data            string            `json:"data"`
+	TotalOrigem     int               `json:"total_origem"`
+	UltimaExtracao  *ExecucaoPipeline `json:"ultima_extracao"`
+	UltimoConsumo   *ExecucaoPipeline `json:"ultimo_consumo"`
+	TotalExtraido   int               `json:"total_extraido"`  // header do último lote extraído
+	TotalConsumido  int               `json:"total_consumido"` // no último consumo
+	TotalProcessado int               `json:"total_processado"`
+	TotalRejeitado  int               `json:"total_rejeitado"`
+	TotalFaltando   int               `json:"total_faltando"` // na origem, sem processado nem rejeitado
+	IDsFaltando     []int             `json:"ids_faltando"`
+	TotalExtras     int               `json:"total_extras"` // processados sem correspondente na origem
+	IDsExtras       []int             `json:"ids_extras"`
+	Alertas         []string          `json:"alertas"`
+}
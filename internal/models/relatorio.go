@@ -0,0 +1,40 @@
+package models
+
+// Agrupamentos aceitos no relatório de resultados
+const (
+	AgruparDia    = "dia"
+	AgruparSemana = "semana" // semana ISO, ex.: 2025-W01
+	AgruparMes    = "mes"
+)
+
+// ContagemStatus é quantos registros de uma tabela têm o status na data
+// (Status vazio com Nulo = status NULL)
+type ContagemStatus struct {
+	Data   string
+	Status string
+	Nulo   bool
+	Total  int
+}
+
+// LinhaRelatorio resume um período. As taxas são frações (0 a 1) sobre o
+// total do período; a variação compara com o período anterior da lista.
+type LinhaRelatorio struct {
+	Periodo               string   `json:"periodo"`
+	Aprovados             int      `json:"aprovados"`
+	Reprovados            int      `json:"reprovados"`
+	Rejeitados            int      `json:"rejeitados"`
+	RejeitadosStatusNulo  int      `json:"rejeitados_status_nulo"`
+	Total                 int      `json:"total"`
+	TaxaAprovacao         float64  `json:"taxa_aprovacao"` // aprovados / (aprovados + reprovados)
+	TaxaNulo              float64  `json:"taxa_nulo"`
+	VariacaoTaxaAprovacao *float64 `json:"variacao_taxa_aprovacao,omitempty"`
+}
+
+// RelatorioResultados é a resposta de GET /relatorios/resultados
+type RelatorioResultados struct {
+	De       string           `json:"de"`
+	Ate      string           `json:"ate"`
+	Agrupar  string           `json:"agrupar"`
+	Periodos []LinhaRelatorio `json:"periodos"`
+	Totais   LinhaRelatorio   `json:"totais"`
+}
@@ -0,0 +1,168 @@
+package rastreamento
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exportadores aceitos em TRACING_EXPORTER
+const (
+	ExportadorNenhum  = "nenhum"
+	ExportadorOTLP    = "otlp"
+	ExportadorStdout  = "stdout"
+	ExportadorArquivo = "arquivo"
+)
+
+const nomeServico = "concurso-go-app"
+
+var tracer = otel.Tracer(nomeServico)
+
+// Iniciar configura o provider conforme TRACING_EXPORTER:
+//   - nenhum (padrão): spans não são gravados, mas o contexto ainda é propagado
+//   - otlp: OTLP/HTTP, configurado pelas variáveis OTEL_EXPORTER_OTLP_* (ex.: OTEL_EXPORTER_OTLP_ENDPOINT)
+//   - stdout: JSON na saída padrão
+//   - arquivo: JSON em TRACING_ARQUIVO (padrão logs/traces.json), para uso offline
+//
+// TRACING_SAMPLE_RATIO (0 a 1, padrão 1) define a fração de traces gravados.
+// A função retornada envia os spans pendentes e fecha o exportador.
+func Iniciar(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exportador := os.Getenv("TRACING_EXPORTER")
+	if exportador == "" {
+		exportador = ExportadorNenhum
+	}
+
+	var spanExporter sdktrace.SpanExporter
+	var arquivo io.Closer
+	switch exportador {
+	case ExportadorNenhum:
+		return func(context.Context) error { return nil }, nil
+	case ExportadorOTLP:
+		exp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao criar exportador OTLP: %w", err)
+		}
+		spanExporter = exp
+	case ExportadorStdout:
+		exp, err := stdouttrace.New()
+		if err != nil {
+			return nil, fmt.Errorf("erro ao criar exportador stdout: %w", err)
+		}
+		spanExporter = exp
+	case ExportadorArquivo:
+		caminho := os.Getenv("TRACING_ARQUIVO")
+		if caminho == "" {
+			caminho = filepath.Join("logs", "traces.json")
+		}
+		if err := os.MkdirAll(filepath.Dir(caminho), 0755); err != nil {
+			return nil, fmt.Errorf("erro ao criar diretório de traces: %w", err)
+		}
+		f, err := os.OpenFile(caminho, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao abrir arquivo de traces: %w", err)
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("erro ao criar exportador de arquivo: %w", err)
+		}
+		spanExporter = exp
+		arquivo = f
+	default:
+		return nil, fmt.Errorf("TRACING_EXPORTER inválido: %s (use nenhum, otlp, stdout ou arquivo)", exportador)
+	}
+
+	proporcao := 1.0
+	if valor := os.Getenv("TRACING_SAMPLE_RATIO"); valor != "" {
+		p, err := strconv.ParseFloat(valor, 64)
+		if err != nil || p < 0 || p > 1 {
+			return nil, fmt.Errorf("TRACING_SAMPLE_RATIO inválido: %s (use um valor entre 0 e 1)", valor)
+		}
+		proporcao = p
+	}
+
+	recurso, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(nomeServico)))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao montar resource do tracing: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(recurso),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(proporcao))),
+	)
+	otel.SetTracerProvider(provider)
+	slog.Info("tracing habilitado", "exportador", exportador, "amostragem", proporcao)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if arquivo != nil {
+			arquivo.Close()
+		}
+		return err
+	}, nil
+}
+
+// Span abre um span filho do span em ctx
+func Span(ctx context.Context, nome string, atributos ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, nome, trace.WithAttributes(atributos...))
+}
+
+// Finalizar marca o span com erro (se houver) e o encerra
+func Finalizar(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceID retorna o trace id do span em ctx, ou "" se não houver trace ativo
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// Injetar grava o contexto de trace de ctx em headers (traceparent/tracestate)
+func Injetar(ctx context.Context, headers map[string]string) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+}
+
+// Extrair devolve ctx com o contexto de trace lido de headers
+func Extrair(ctx context.Context, headers map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(headers))
+}
+
+// Continuar abre um span que continua o trace gravado em headers (ex.: o
+// header Kafka do lote), com link para o span atual de ctx. Sem trace nos
+// headers é um span filho comum.
+func Continuar(ctx context.Context, headers map[string]string, nome string, atributos ...attribute.KeyValue) (context.Context, trace.Span) {
+	origem := trace.SpanContextFromContext(Extrair(context.Background(), headers))
+	if !origem.IsValid() {
+		return Span(ctx, nome, atributos...)
+	}
+	return tracer.Start(trace.ContextWithRemoteSpanContext(ctx, origem), nome,
+		trace.WithAttributes(atributos...),
+		trace.WithLinks(trace.LinkFromContext(ctx)),
+	)
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"concurso-go-app/internal/models"
+)
+
+func (r *repositorioSQL) ConsultarConcursos(ctx context.Context, filtro models.FiltroConsulta) ([]models.Concurso, error) {
+	query, args := r.montarConsulta("concurso", "id, nome, status, data_prova", filtro)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar concurso: %w", err)
+	}
+	defer rows.Close()
+
+	registros := []models.Concurso{}
+	for rows.Next() {
+		var c models.Concurso
+		if err := rows.Scan(&c.ID, &c.Nome, &c.Status, &c.DataProva); err != nil {
+			return nil, fmt.Errorf("erro ao ler registro: %w", err)
+		}
+		registros = append(registros, c)
+	}
+	return registros, rows.Err()
+}
+
+func (r *repositorioSQL) ConsultarProcessados(ctx context.Context, filtro models.FiltroConsulta) ([]models.ConcursoProcessado, error) {
+	query, args := r.montarConsulta("concurso_processado", "id, nome, status, data_prova, lote", filtro)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar concurso_processado: %w", err)
+	}
+	defer rows.Close()
+
+	registros := []models.ConcursoProcessado{}
+	for rows.Next() {
+		var c models.ConcursoProcessado
+		if err := rows.Scan(&c.ID, &c.Nome, &c.Status, &c.DataProva, &c.Lote); err != nil {
+			return nil, fmt.Errorf("erro ao ler registro: %w", err)
+		}
+		registros = append(registros, c)
+	}
+	return registros, rows.Err()
+}
+
+// montarConsulta gera o SELECT com filtros e paginação por cursor (keyset):
+// em vez de OFFSET, continua a partir de (campo, id) do último registro. O
+// filtro já deve ter sido validado (Ordem é interpolada na query).
+func (r *repositorioSQL) montarConsulta(tabela string, colunas string, filtro models.FiltroConsulta) (string, []interface{}) {
+	var condicoes []string
+	var args []interface{}
+
+	if filtro.Data != "" {
+		condicoes = append(condicoes, "data_prova = ?")
+		args = append(args, filtro.Data)
+	}
+	switch filtro.Status {
+	case "":
+	case "null":
+		condicoes = append(condicoes, "status IS NULL")
+	default:
+		condicoes = append(condicoes, "status = ?")
+		args = append(args, filtro.Status)
+	}
+	if filtro.PrefixoNome != "" {
+		// "!" como escape funciona igual nos três bancos
+		condicoes = append(condicoes, "nome LIKE ? ESCAPE '!'")
+		args = append(args, escaparLike(filtro.PrefixoNome)+"%")
+	}
+	if filtro.Lote != "" {
+		condicoes = append(condicoes, "lote = ?")
+		args = append(args, filtro.Lote)
+	}
+
+	ordem := filtro.Ordem
+	if ordem == "" {
+		ordem = "id"
+	}
+	comparador, direcao := ">", "ASC"
+	if filtro.Decrescente {
+		comparador, direcao = "<", "DESC"
+	}
+
+	if filtro.Cursor != nil {
+		if ordem == "id" {
+			condicoes = append(condicoes, "id "+comparador+" ?")
+			args = append(args, filtro.Cursor.ID)
+		} else {
+			condicoes = append(condicoes, fmt.Sprintf("(%s %s ? OR (%s = ? AND id %s ?))", ordem, comparador, ordem, comparador))
+			args = append(args, filtro.Cursor.Valor, filtro.Cursor.Valor, filtro.Cursor.ID)
+		}
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", colunas, tabela)
+	if len(condicoes) > 0 {
+		query += " WHERE " + strings.Join(condicoes, " AND ")
+	}
+	if ordem == "id" {
+		query += " ORDER BY id " + direcao
+	} else {
+		query += fmt.Sprintf(" ORDER BY %s %s, id %s", ordem, direcao, direcao)
+	}
+	query += " LIMIT ?"
+	args = append(args, filtro.Limite)
+
+	return r.placeholder(query), args
+}
+
+var escapeLike = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+func escaparLike(valor string) string {
+	return escapeLike.Replace(valor)
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"concurso-go-app/internal/database"
+	"concurso-go-app/internal/models"
+)
+
+// Métodos de gravação informados por Geracao.Metodo
+const (
+	MetodoCargaEmMassa = "carga_em_massa"
+	MetodoInsertLote   = "insert_lote"
+)
+
+// geracaoSQL grava em concurso_staging, identificada pela carga; a coluna
+// ordem guarda registro.ID para Publicar manter a ordem de geração em cada dia
+type geracaoSQL struct {
+	repo    *repositorioSQL
+	carga   string
+	emMassa atomic.Bool
+}
+
+func (r *repositorioSQL) IniciarGeracao(emMassa bool) Geracao {
+	g := &geracaoSQL{repo: r, carga: novoIDCarga()}
+	g.emMassa.Store(emMassa && r.emMassa != nil)
+	return g
+}
+
+func (g *geracaoSQL) opcoes() opcoesInsercao {
+	return opcoesInsercao{
+		fixas:    []colunaFixa{{"carga", g.carga}},
+		colunaID: "ordem",
+		comRetry: true,
+	}
+}
+
+// Inserir usa a carga em massa enquanto o banco aceitar; na primeira recusa
+// passa, para esta e as próximas chamadas, aos INSERTs em lote
+func (g *geracaoSQL) Inserir(ctx context.Context, registros []models.Concurso) error {
+	if g.emMassa.Load() {
+		err := g.repo.emMassa(ctx, "concurso_staging", registros, g.opcoes())
+		if !errors.Is(err, ErrCargaEmMassaIndisponivel) {
+			return err
+		}
+		g.emMassa.Store(false)
+	}
+	return g.repo.inserirEmLotes(ctx, g.repo.db, "concurso_staging", registros, g.opcoes())
+}
+
+func (g *geracaoSQL) Metodo() string {
+	if g.emMassa.Load() {
+		return MetodoCargaEmMassa
+	}
+	return MetodoInsertLote
+}
+
+// Publicar apaga concurso (se substituir) e move a staging numa única
+// transação: quem lê concurso vê a massa anterior ou a nova, nunca metade
+func (g *geracaoSQL) Publicar(ctx context.Context, substituir bool) error {
+	r := g.repo
+	return database.ComRetry(ctx, func() error {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if substituir {
+			if _, err := tx.ExecContext(ctx, "DELETE FROM concurso"); err != nil {
+				return fmt.Errorf("erro ao limpar tabela concurso: %w", err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, r.placeholder(`
+			INSERT INTO concurso (nome, status, data_prova)
+			SELECT nome, status, data_prova FROM concurso_staging WHERE carga = ?
+			ORDER BY data_prova, ordem
+		`), g.carga); err != nil {
+			return fmt.Errorf("erro ao mover staging para concurso: %w", err)
+		}
+		return tx.Commit()
+	})
+}
+
+// Descartar roda mesmo com o contexto cancelado
+func (g *geracaoSQL) Descartar(ctx context.Context) {
+	g.repo.db.ExecContext(context.WithoutCancel(ctx), g.repo.placeholder("DELETE FROM concurso_staging WHERE carga = ?"), g.carga)
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"concurso-go-app/internal/models"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Códigos do MySQL quando LOAD DATA LOCAL está desabilitado no servidor ou no cliente
+const (
+	erroComandoNaoPermitido = 1148
+	erroLocalInfileServidor = 3948
+	erroLocalInfileCliente  = 2068
+)
+
+// RepositorioMySQL usa INSERT multi-row com até 1000 linhas por comando e,
+// como carga em massa, LOAD DATA LOCAL INFILE
+type RepositorioMySQL struct {
+	repositorioSQL
+}
+
+func NovoRepositorioMySQL(db *sql.DB) *RepositorioMySQL {
+	r := &RepositorioMySQL{repositorioSQL{db: db, placeholder: semRebind, linhasPorInsert: 1000}}
+	r.emMassa = r.loadData
+	return r
+}
+
+// CarregarProcessados envia os registros por LOAD DATA LOCAL INFILE para a
+// tabela de staging e depois os move para concurso_processado numa transação,
+// então ou entra o lote inteiro ou nada. Retorna ErrCargaEmMassaIndisponivel
+// se local_infile estiver desabilitado.
+func (r *RepositorioMySQL) CarregarProcessados(ctx context.Context, lote string, registros []models.Concurso) error {
+	carga := novoIDCarga()
+	defer r.db.ExecContext(context.WithoutCancel(ctx), "DELETE FROM concurso_processado_staging WHERE carga = ?", carga)
+
+	opcoes := opcoesInsercao{
+		fixas:    []colunaFixa{{"carga", carga}, {"lote", lote}},
+		colunaID: "concurso_id",
+	}
+	if err := r.loadData(ctx, "concurso_processado_staging", registros, opcoes); err != nil {
+		return err
+	}
+	return r.moverProcessados(ctx, carga)
+}
+
+// loadData grava os registros na tabela com um LOAD DATA LOCAL INFILE. Os
+// dados vão em streaming, sem arquivo temporário.
+func (r *RepositorioMySQL) loadData(ctx context.Context, tabela string, registros []models.Concurso, opcoes opcoesInsercao) error {
+	nomeHandler := "concurso_" + novoIDCarga()
+	mysql.RegisterReaderHandler(nomeHandler, func() io.Reader {
+		leitor, escritor := io.Pipe()
+		go func() {
+			escritor.CloseWithError(escreverTSV(escritor, registros, opcoes))
+		}()
+		return leitor
+	})
+	defer mysql.DeregisterReaderHandler(nomeHandler)
+
+	// LOAD DATA não aceita placeholders; tabela e colunas são fixas no código
+	query := fmt.Sprintf(`
+		LOAD DATA LOCAL INFILE 'Reader::%s'
+		INTO TABLE %s
+		CHARACTER SET utf8mb4
+		FIELDS TERMINATED BY '\t' ESCAPED BY '\\'
+		LINES TERMINATED BY '\n'
+		(%s)
+	`, nomeHandler, tabela, strings.Join(opcoes.colunas(), ", "))
+
+	if _, err := r.db.ExecContext(ctx, query); err != nil {
+		if localInfileDesabilitado(err) {
+			return fmt.Errorf("%w: %v", ErrCargaEmMassaIndisponivel, err)
+		}
+		return fmt.Errorf("erro no LOAD DATA: %w", err)
+	}
+	return nil
+}
+
+// escreverTSV gera as linhas no formato padrão do LOAD DATA (tab, \N para
+// NULL), com as colunas na ordem de opcoes.colunas
+func escreverTSV(w io.Writer, registros []models.Concurso, opcoes opcoesInsercao) error {
+	buf := bufio.NewWriterSize(w, 64*1024)
+	campos := make([]string, 0, len(opcoes.colunas()))
+	valores := make([]interface{}, 0, cap(campos))
+	for _, registro := range registros {
+		campos, valores = campos[:0], opcoes.valores(valores[:0], registro)
+		for _, valor := range valores {
+			campos = append(campos, campoTSV(valor))
+		}
+		if _, err := buf.WriteString(strings.Join(campos, "\t") + "\n"); err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
+
+func campoTSV(valor interface{}) string {
+	switch v := valor.(type) {
+	case sql.NullString:
+		if !v.Valid {
+			return `\N`
+		}
+		return escaparTSV(v.String)
+	case string:
+		return escaparTSV(v)
+	default:
+		return escaparTSV(fmt.Sprint(v))
+	}
+}
+
+var escapeTSV = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func escaparTSV(valor string) string {
+	return escapeTSV.Replace(valor)
+}
+
+func localInfileDesabilitado(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case erroComandoNaoPermitido, erroLocalInfileServidor, erroLocalInfileCliente:
+			return true
+		}
+	}
+	return false
+}
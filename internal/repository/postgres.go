@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"concurso-go-app/internal/database"
+	"concurso-go-app/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// RepositorioPostgres usa placeholders $n e, como carga em massa, COPY FROM STDIN
+type RepositorioPostgres struct {
+	repositorioSQL
+}
+
+func NovoRepositorioPostgres(db *sql.DB) *RepositorioPostgres {
+	r := &RepositorioPostgres{repositorioSQL{db: db, placeholder: database.Rebind, linhasPorInsert: 1000}}
+	r.emMassa = r.copiar
+	return r
+}
+
+// CarregarProcessados grava concurso_processado com COPY numa transação,
+// bem mais rápido que INSERT multi-row para lotes grandes
+func (r *RepositorioPostgres) CarregarProcessados(ctx context.Context, lote string, registros []models.Concurso) error {
+	return r.copiar(ctx, "concurso_processado", registros, opcoesInsercao{
+		fixas:    []colunaFixa{{"lote", lote}},
+		colunaID: "concurso_id",
+	})
+}
+
+// copiar grava os registros na tabela com COPY FROM STDIN numa transação,
+// repetida por inteiro em erros transitórios
+func (r *RepositorioPostgres) copiar(ctx context.Context, tabela string, registros []models.Concurso, opcoes opcoesInsercao) error {
+	return database.ComRetry(ctx, func() error {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		stmt, err := tx.PrepareContext(ctx, pq.CopyIn(tabela, opcoes.colunas()...))
+		if err != nil {
+			return fmt.Errorf("erro ao preparar COPY: %w", err)
+		}
+		args := make([]interface{}, 0, len(opcoes.colunas()))
+		for _, registro := range registros {
+			if _, err := stmt.ExecContext(ctx, opcoes.valores(args[:0], registro)...); err != nil {
+				stmt.Close()
+				return err
+			}
+		}
+		// Exec sem argumentos envia os dados pendentes
+		if _, err := stmt.ExecContext(ctx); err != nil {
+			stmt.Close()
+			return err
+		}
+		if err := stmt.Close(); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"concurso-go-app/internal/database"
+	"concurso-go-app/internal/models"
+)
+
+// tamanhoMotivo é o tamanho das colunas motivo
+const tamanhoMotivo = 1000
+
+// tamanhoPrincipal é o tamanho da coluna principal de pipeline_execucao
+const tamanhoPrincipal = 255
+
+func (r *repositorioSQL) RegistrarExecucao(ctx context.Context, execucao models.ExecucaoPipeline) error {
+	// Um INSERT em autocommit: nos erros de ErroTransitorio ele não foi
+	// aplicado, então repetir não grava a execução duas vezes
+	return database.ComRetry(ctx, func() error {
+		_, err := r.db.ExecContext(ctx, r.placeholder(`
+			INSERT INTO pipeline_execucao (tipo, data, lote, trace_id, total, status, motivo, principal)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`), execucao.Tipo, execucao.Data, execucao.Lote, execucao.TraceID, execucao.Total, execucao.Status, truncar(execucao.Motivo, tamanhoMotivo), truncar(execucao.Principal, tamanhoPrincipal))
+		return err
+	})
+}
+
+// RegistrarRejeitados substitui os rejeitados do lote na data, então consumir
+// o mesmo lote de novo não duplica as linhas
+func (r *repositorioSQL) RegistrarRejeitados(ctx context.Context, data string, lote string, motivo string, registros []models.Concurso) error {
+	return database.ComRetry(ctx, func() error {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.ExecContext(ctx, r.placeholder("DELETE FROM concurso_rejeitado WHERE data = ? AND lote = ?"), data, lote); err != nil {
+			return err
+		}
+		opcoes := opcoesInsercao{
+			fixas:    []colunaFixa{{"data", data}, {"lote", lote}, {"motivo", truncar(motivo, tamanhoMotivo)}},
+			colunaID: "concurso_id",
+		}
+		if err := r.inserirEmLotes(ctx, tx, "concurso_rejeitado", registros, opcoes); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// Reconciliar junta, por data entre de e ate, as contagens das tabelas, as
+// últimas execuções e até limiteIDs ids faltando/extras. Os alertas ficam
+// com o serviço.
+func (r *repositorioSQL) Reconciliar(ctx context.Context, de string, ate string, limiteIDs int) ([]models.ReconciliacaoData, error) {
+	porData := make(map[string]*models.ReconciliacaoData)
+	linha := func(data time.Time) *models.ReconciliacaoData {
+		chave := data.Format(FormatoData)
+		item, ok := porData[chave]
+		if !ok {
+			item = &models.ReconciliacaoData{Data: chave, IDsFaltando: []int{}, IDsExtras: []int{}, Alertas: []string{}}
+			porData[chave] = item
+		}
+		return item
+	}
+
+	contagens := []struct {
+		query   string
+		destino func(*models.ReconciliacaoData) *int
+	}{
+		{`SELECT data_prova, COUNT(*) FROM concurso WHERE data_prova BETWEEN ? AND ? GROUP BY data_prova`,
+			func(d *models.ReconciliacaoData) *int { return &d.TotalOrigem }},
+		{`SELECT data_prova, COUNT(*) FROM concurso_processado WHERE data_prova BETWEEN ? AND ? GROUP BY data_prova`,
+			func(d *models.ReconciliacaoData) *int { return &d.TotalProcessado }},
+		{`SELECT data, COUNT(*) FROM concurso_rejeitado WHERE data BETWEEN ? AND ? GROUP BY data`,
+			func(d *models.ReconciliacaoData) *int { return &d.TotalRejeitado }},
+		// Na origem sem processado nem rejeitado
+		{`SELECT c.data_prova, COUNT(*) FROM concurso c
+			WHERE c.data_prova BETWEEN ? AND ?
+			AND NOT EXISTS (SELECT 1 FROM concurso_processado p WHERE p.concurso_id = c.id)
+			AND NOT EXISTS (SELECT 1 FROM concurso_rejeitado j WHERE j.concurso_id = c.id)
+			GROUP BY c.data_prova`,
+			func(d *models.ReconciliacaoData) *int { return &d.TotalFaltando }},
+		// Processados cujo id não existe na origem (ou mudou de data)
+		{`SELECT p.data_prova, COUNT(*) FROM concurso_processado p
+			WHERE p.data_prova BETWEEN ? AND ?
+			AND NOT EXISTS (SELECT 1 FROM concurso c WHERE c.id = p.concurso_id AND c.data_prova = p.data_prova)
+			GROUP BY p.data_prova`,
+			func(d *models.ReconciliacaoData) *int { return &d.TotalExtras }},
+	}
+	for _, contagem := range contagens {
+		err := r.consultarPorData(ctx, contagem.query, []interface{}{de, ate}, func(rows *sql.Rows) error {
+			var data time.Time
+			var total int
+			if err := rows.Scan(&data, &total); err != nil {
+				return err
+			}
+			*contagem.destino(linha(data)) = total
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("erro na reconciliação: %w", err)
+		}
+	}
+
+	// Última extração e último consumo de cada data (ordem de id = ordem de execução)
+	err := r.consultarPorData(ctx, `
+		SELECT data, tipo, lote, trace_id, total, status, motivo, principal FROM pipeline_execucao
+		WHERE data BETWEEN ? AND ? ORDER BY id
+	`, []interface{}{de, ate}, func(rows *sql.Rows) error {
+		var data time.Time
+		var e models.ExecucaoPipeline
+		if err := rows.Scan(&data, &e.Tipo, &e.Lote, &e.TraceID, &e.Total, &e.Status, &e.Motivo, &e.Principal); err != nil {
+			return err
+		}
+		e.Data = data.Format(FormatoData)
+		item := linha(data)
+		switch e.Tipo {
+		case models.ExecucaoExtracao:
+			item.UltimaExtracao = &e
+			item.TotalExtraido = e.Total
+		case models.ExecucaoConsumo:
+			item.UltimoConsumo = &e
+			item.TotalConsumido = e.Total
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler execuções: %w", err)
+	}
+
+	// Amostra dos ids, só para as datas com diferença
+	for _, item := range porData {
+		if item.TotalFaltando > 0 {
+			if item.IDsFaltando, err = r.listarIDs(ctx, `
+				SELECT c.id FROM concurso c
+				WHERE c.data_prova = ?
+				AND NOT EXISTS (SELECT 1 FROM concurso_processado p WHERE p.concurso_id = c.id)
+				AND NOT EXISTS (SELECT 1 FROM concurso_rejeitado j WHERE j.concurso_id = c.id)
+				ORDER BY c.id LIMIT ?
+			`, item.Data, limiteIDs); err != nil {
+				return nil, err
+			}
+		}
+		if item.TotalExtras > 0 {
+			if item.IDsExtras, err = r.listarIDs(ctx, `
+				SELECT COALESCE(p.concurso_id, 0) FROM concurso_processado p
+				WHERE p.data_prova = ?
+				AND NOT EXISTS (SELECT 1 FROM concurso c WHERE c.id = p.concurso_id AND c.data_prova = p.data_prova)
+				ORDER BY p.concurso_id LIMIT ?
+			`, item.Data, limiteIDs); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	resultado := make([]models.ReconciliacaoData, 0, len(porData))
+	for _, item := range porData {
+		resultado = append(resultado, *item)
+	}
+	sort.Slice(resultado, func(i, j int) bool { return resultado[i].Data < resultado[j].Data })
+	return resultado, nil
+}
+
+// ContarDuplicados retorna, por data, quantos concurso_id aparecem mais de uma
+// vez em concurso_processado (lote consumido duas vezes, por exemplo)
+func (r *repositorioSQL) ContarDuplicados(ctx context.Context, de string, ate string) (map[string]int, error) {
+	duplicados := make(map[string]int)
+	err := r.consultarPorData(ctx, `
+		SELECT data_prova, COUNT(*) FROM (
+			SELECT data_prova, concurso_id FROM concurso_processado
+			WHERE data_prova BETWEEN ? AND ? AND concurso_id IS NOT NULL
+			GROUP BY data_prova, concurso_id HAVING COUNT(*) > 1
+		) d GROUP BY data_prova
+	`, []interface{}{de, ate}, func(rows *sql.Rows) error {
+		var data time.Time
+		var total int
+		if err := rows.Scan(&data, &total); err != nil {
+			return err
+		}
+		duplicados[data.Format(FormatoData)] = total
+		return nil
+	})
+	return duplicados, err
+}
+
+func (r *repositorioSQL) consultarPorData(ctx context.Context, query string, args []interface{}, ler func(*sql.Rows) error) error {
+	return database.ComRetry(ctx, func() error {
+		rows, err := r.db.QueryContext(ctx, r.placeholder(query), args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := ler(rows); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	})
+}
+
+func (r *repositorioSQL) listarIDs(ctx context.Context, query string, data string, limite int) ([]int, error) {
+	ids := []int{}
+	err := r.consultarPorData(ctx, query, []interface{}{data, limite}, func(rows *sql.Rows) error {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar ids de %s: %w", data, err)
+	}
+	return ids, nil
+}
+
+// truncar corta em caracteres (não bytes), como o VARCHAR conta
+func truncar(texto string, tamanho int) string {
+	runas := []rune(texto)
+	if len(runas) <= tamanho {
+		return texto
+	}
+	return string(runas[:tamanho])
+}
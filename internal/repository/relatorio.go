@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"concurso-go-app/internal/models"
+)
+
+// ContarProcessadosPorStatus agrega concurso_processado por data_prova e status
+func (r *repositorioSQL) ContarProcessadosPorStatus(ctx context.Context, de string, ate string) ([]models.ContagemStatus, error) {
+	return r.contarPorStatus(ctx, "concurso_processado", de, ate)
+}
+
+// ContarRejeitadosPorStatus agrega concurso_rejeitado por data_prova e status
+func (r *repositorioSQL) ContarRejeitadosPorStatus(ctx context.Context, de string, ate string) ([]models.ContagemStatus, error) {
+	return r.contarPorStatus(ctx, "concurso_rejeitado", de, ate)
+}
+
+// contarPorStatus agrupa só por data; semana e mês são montados pelo serviço,
+// o que evita funções de data diferentes em cada banco
+func (r *repositorioSQL) contarPorStatus(ctx context.Context, tabela string, de string, ate string) ([]models.ContagemStatus, error) {
+	contagens := []models.ContagemStatus{}
+	query := fmt.Sprintf(`
+		SELECT data_prova, status, COUNT(*) FROM %s
+		WHERE data_prova BETWEEN ? AND ?
+		GROUP BY data_prova, status
+		ORDER BY data_prova
+	`, tabela)
+	err := r.consultarPorData(ctx, query, []interface{}{de, ate}, func(rows *sql.Rows) error {
+		var data time.Time
+		var status sql.NullString
+		var total int
+		if err := rows.Scan(&data, &status, &total); err != nil {
+			return err
+		}
+		contagens = append(contagens, models.ContagemStatus{
+			Data:   data.Format(FormatoData),
+			Status: status.String,
+			Nulo:   !status.Valid,
+			Total:  total,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao agregar %s: %w", tabela, err)
+	}
+	return contagens, nil
+}
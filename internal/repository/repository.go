@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"concurso-go-app/internal/database"
+	"concurso-go-app/internal/models"
+)
+
+// ConcursoRepository concentra o SQL das tabelas concurso e concurso_processado.
+// Cada banco suportado (DB_DRIVER) tem sua implementação. Todos os métodos
+// respeitam o cancelamento do contexto.
+type ConcursoRepository interface {
+	// Ping verifica se o banco está no ar
+	Ping(ctx context.Context) error
+	// IniciarGeracao prepara a carga dos registros de teste em concurso; com
+	// emMassa usa a carga em massa do banco, se houver
+	IniciarGeracao(emMassa bool) Geracao
+	// ContarPorData retorna quantos registros existem para a data (YYYY-MM-DD)
+	ContarPorData(ctx context.Context, data string) (int, error)
+	// BuscarPorData retorna uma página dos registros da data, ordenada por id
+	BuscarPorData(ctx context.Context, data string, limite int, offset int) ([]models.Concurso, error)
+	// InserirProcessados grava registros validados do lote em concurso_processado com
+	// até workers inserções em paralelo; ou entram todos ou nenhum.
+	// progresso, se informado, recebe o tamanho de cada lote gravado.
+	InserirProcessados(ctx context.Context, lote string, registros []models.Concurso, workers int, progresso func(inseridos int)) error
+	// ConsultarConcursos e ConsultarProcessados listam uma página de registros
+	// conforme os filtros e o cursor
+	ConsultarConcursos(ctx context.Context, filtro models.FiltroConsulta) ([]models.Concurso, error)
+	ConsultarProcessados(ctx context.Context, filtro models.FiltroConsulta) ([]models.ConcursoProcessado, error)
+	// RegistrarExecucao grava uma extração ou consumo em pipeline_execucao
+	RegistrarExecucao(ctx context.Context, execucao models.ExecucaoPipeline) error
+	// RegistrarRejeitados grava os registros de um lote rejeitado
+	RegistrarRejeitados(ctx context.Context, data string, lote string, motivo string, registros []models.Concurso) error
+	// Reconciliar e ContarDuplicados alimentam GET /reconciliacao
+	Reconciliar(ctx context.Context, de string, ate string, limiteIDs int) ([]models.ReconciliacaoData, error)
+	ContarDuplicados(ctx context.Context, de string, ate string) (map[string]int, error)
+	// ContarProcessadosPorStatus e ContarRejeitadosPorStatus alimentam o relatório de resultados
+	ContarProcessadosPorStatus(ctx context.Context, de string, ate string) ([]models.ContagemStatus, error)
+	ContarRejeitadosPorStatus(ctx context.Context, de string, ate string) ([]models.ContagemStatus, error)
+}
+
+// ErrCargaEmMassaIndisponivel indica que o banco recusou a carga em massa;
+// quem chamou deve usar os INSERTs em lote
+var ErrCargaEmMassaIndisponivel = errors.New("carga em massa indisponível")
+
+// CarregadorEmMassa é implementado pelos repositórios que têm um caminho de
+// carga mais rápido que o INSERT multi-row
+type CarregadorEmMassa interface {
+	CarregarProcessados(ctx context.Context, lote string, registros []models.Concurso) error
+}
+
+// Geracao carrega a massa de teste (POST /start) em concurso_staging, por
+// vários workers ao mesmo tempo; nada aparece em concurso até Publicar
+type Geracao interface {
+	// Inserir grava registros na staging; registro.ID dá a ordem dentro do dia
+	Inserir(ctx context.Context, registros []models.Concurso) error
+	// Publicar move a staging para concurso numa transação, apagando antes a
+	// tabela se substituir; em erro, concurso fica como estava
+	Publicar(ctx context.Context, substituir bool) error
+	// Descartar apaga da staging as linhas desta geração (usar com defer)
+	Descartar(ctx context.Context)
+	// Metodo informa como os registros foram gravados (MetodoCargaEmMassa ou MetodoInsertLote)
+	Metodo() string
+}
+
+// Novo retorna o repositório do banco informado (database.Driver)
+func Novo(db *sql.DB, driver string) ConcursoRepository {
+	switch driver {
+	case database.DriverPostgres:
+		return NovoRepositorioPostgres(db)
+	case database.DriverSQLite:
+		return NovoRepositorioSQLite(db)
+	default:
+		return NovoRepositorioMySQL(db)
+	}
+}
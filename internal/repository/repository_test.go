@@ -0,0 +1,484 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"concurso-go-app/internal/database"
+	"concurso-go-app/internal/migrations"
+	"concurso-go-app/internal/models"
+
+	_ "modernc.org/sqlite"
+)
+
+var ctx = context.Background()
+
+// novoRepositorio abre um SQLite vazio num diretório temporário, com todas as migrations
+func novoRepositorio(t *testing.T) (ConcursoRepository, *sql.DB) {
+	t.Helper()
+	database.Driver = database.DriverSQLite
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "teste.db")+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	versao, err := migrations.UltimaVersao(database.DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrations.Aplicar(db, database.DriverSQLite, versao); err != nil {
+		t.Fatal(err)
+	}
+	return Novo(db, database.DriverSQLite), db
+}
+
+func data(t *testing.T, texto string) time.Time {
+	t.Helper()
+	d, err := time.Parse(FormatoData, texto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func status(valor string) sql.NullString {
+	return sql.NullString{String: valor, Valid: valor != ""}
+}
+
+// gerar monta n registros da data, com ID = ordem no dia e os status em ciclo
+func gerar(t *testing.T, dia string, n int, statuses ...string) []models.Concurso {
+	t.Helper()
+	registros := make([]models.Concurso, n)
+	for i := range registros {
+		registros[i] = models.Concurso{
+			ID:        i + 1,
+			Nome:      fmt.Sprintf("Candidato_%d_%s", i+1, dia),
+			Status:    status(statuses[i%len(statuses)]),
+			DataProva: data(t, dia),
+		}
+	}
+	return registros
+}
+
+// popular publica os registros em concurso pelo caminho do POST /start
+func popular(t *testing.T, repo ConcursoRepository, substituir bool, registros ...[]models.Concurso) {
+	t.Helper()
+	geracao := repo.IniciarGeracao(false)
+	defer geracao.Descartar(ctx)
+	for _, parte := range registros {
+		if err := geracao.Inserir(ctx, parte); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := geracao.Publicar(ctx, substituir); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func contar(t *testing.T, db *sql.DB, query string, args ...interface{}) int {
+	t.Helper()
+	var total int
+	if err := db.QueryRow(query, args...).Scan(&total); err != nil {
+		t.Fatal(err)
+	}
+	return total
+}
+
+func TestPing(t *testing.T) {
+	repo, _ := novoRepositorio(t)
+	if err := repo.Ping(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGeracaoPublicaEmOrdem(t *testing.T) {
+	repo, db := novoRepositorio(t)
+
+	// Os dias chegam fora de ordem (workers em paralelo); concurso recebe os
+	// ids por data e pela ordem de geração dentro do dia
+	dia2 := gerar(t, "2025-01-02", 3, "aprovado")
+	dia1 := gerar(t, "2025-01-01", 2500, "aprovado", "", "reprovado")
+	geracao := repo.IniciarGeracao(true)
+	defer geracao.Descartar(ctx)
+	var wg sync.WaitGroup
+	erros := make(chan error, 2)
+	for _, parte := range [][]models.Concurso{dia2, dia1} {
+		wg.Add(1)
+		go func(parte []models.Concurso) {
+			defer wg.Done()
+			erros <- geracao.Inserir(ctx, parte)
+		}(parte)
+	}
+	wg.Wait()
+	close(erros)
+	for err := range erros {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := contar(t, db, "SELECT COUNT(*) FROM concurso"); n != 0 {
+		t.Fatalf("concurso tem %d registros antes de Publicar", n)
+	}
+	if err := geracao.Publicar(ctx, true); err != nil {
+		t.Fatal(err)
+	}
+	// SQLite não tem carga em massa: mesmo pedindo, grava por INSERT em lote
+	if metodo := geracao.Metodo(); metodo != MetodoInsertLote {
+		t.Fatalf("Metodo() = %s", metodo)
+	}
+
+	registros, err := repo.BuscarPorData(ctx, "2025-01-01", 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registros) != 2 || registros[0].ID != 1 || registros[0].Nome != "Candidato_1_2025-01-01" || registros[1].Status.Valid {
+		t.Fatalf("primeiros registros de 2025-01-01 = %+v", registros)
+	}
+	registros, err = repo.BuscarPorData(ctx, "2025-01-02", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registros) != 3 || registros[0].ID != 2501 || registros[2].Nome != "Candidato_3_2025-01-02" {
+		t.Fatalf("registros de 2025-01-02 = %+v", registros)
+	}
+
+	geracao.Descartar(ctx)
+	if n := contar(t, db, "SELECT COUNT(*) FROM concurso_staging"); n != 0 {
+		t.Fatalf("staging tem %d linhas depois de Descartar", n)
+	}
+}
+
+func TestGeracaoSubstituirOuAcrescentar(t *testing.T) {
+	repo, db := novoRepositorio(t)
+	popular(t, repo, true, gerar(t, "2025-01-01", 5, "aprovado"))
+
+	popular(t, repo, false, gerar(t, "2025-01-02", 3, "aprovado"))
+	if n := contar(t, db, "SELECT COUNT(*) FROM concurso"); n != 8 {
+		t.Fatalf("acrescentar: %d registros, esperado 8", n)
+	}
+
+	popular(t, repo, true, gerar(t, "2025-01-03", 2, "aprovado"))
+	if n := contar(t, db, "SELECT COUNT(*) FROM concurso"); n != 2 {
+		t.Fatalf("substituir: %d registros, esperado 2", n)
+	}
+}
+
+func TestGeracaoDescartadaNaoAlteraConcurso(t *testing.T) {
+	repo, db := novoRepositorio(t)
+	popular(t, repo, true, gerar(t, "2025-01-01", 5, "aprovado"))
+
+	// Um worker falhou: a geração é descartada sem publicar
+	geracao := repo.IniciarGeracao(false)
+	if err := geracao.Inserir(ctx, gerar(t, "2025-01-02", 10, "aprovado")); err != nil {
+		t.Fatal(err)
+	}
+	geracao.Descartar(ctx)
+
+	if n := contar(t, db, "SELECT COUNT(*) FROM concurso"); n != 5 {
+		t.Fatalf("concurso tem %d registros, esperado os 5 anteriores", n)
+	}
+	if n := contar(t, db, "SELECT COUNT(*) FROM concurso_staging"); n != 0 {
+		t.Fatalf("staging tem %d linhas", n)
+	}
+}
+
+func TestContarEBuscarPorData(t *testing.T) {
+	repo, _ := novoRepositorio(t)
+	popular(t, repo, true, gerar(t, "2025-01-01", 7, "aprovado"), gerar(t, "2025-01-02", 2, "aprovado"))
+
+	total, err := repo.ContarPorData(ctx, "2025-01-01")
+	if err != nil || total != 7 {
+		t.Fatalf("ContarPorData = %d, %v", total, err)
+	}
+
+	var ids []int
+	for offset := 0; ; offset += 3 {
+		pagina, err := repo.BuscarPorData(ctx, "2025-01-01", 3, offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pagina) == 0 {
+			break
+		}
+		for _, registro := range pagina {
+			if registro.DataProva.Format(FormatoData) != "2025-01-01" {
+				t.Fatalf("registro de outra data: %+v", registro)
+			}
+			ids = append(ids, registro.ID)
+		}
+	}
+	if !reflect.DeepEqual(ids, []int{1, 2, 3, 4, 5, 6, 7}) {
+		t.Fatalf("ids paginados = %v", ids)
+	}
+}
+
+func TestInserirProcessados(t *testing.T) {
+	repo, db := novoRepositorio(t)
+	registros := gerar(t, "2025-01-01", 2500, "aprovado", "reprovado")
+
+	var progresso int64
+	err := repo.InserirProcessados(ctx, "lote-1", registros, 3, func(inseridos int) {
+		atomic.AddInt64(&progresso, int64(inseridos))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progresso != 2500 {
+		t.Fatalf("progresso = %d, esperado 2500", progresso)
+	}
+	if n := contar(t, db, "SELECT COUNT(*) FROM concurso_processado WHERE lote = ?", "lote-1"); n != 2500 {
+		t.Fatalf("%d processados, esperado 2500", n)
+	}
+	if n := contar(t, db, "SELECT COUNT(DISTINCT concurso_id) FROM concurso_processado"); n != 2500 {
+		t.Fatalf("%d concurso_id distintos, esperado 2500", n)
+	}
+	if n := contar(t, db, "SELECT COUNT(*) FROM concurso_processado_staging"); n != 0 {
+		t.Fatalf("staging tem %d linhas", n)
+	}
+}
+
+func TestInserirProcessadosCanceladoNaoGravaNada(t *testing.T) {
+	repo, db := novoRepositorio(t)
+	cancelado, cancelar := context.WithCancel(ctx)
+	cancelar()
+
+	err := repo.InserirProcessados(cancelado, "lote-1", gerar(t, "2025-01-01", 10, "aprovado"), 2, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("erro = %v, esperado context.Canceled", err)
+	}
+	if n := contar(t, db, "SELECT COUNT(*) FROM concurso_processado"); n != 0 {
+		t.Fatalf("%d processados gravados", n)
+	}
+	if n := contar(t, db, "SELECT COUNT(*) FROM concurso_processado_staging"); n != 0 {
+		t.Fatalf("staging tem %d linhas", n)
+	}
+}
+
+func TestConsultarConcursos(t *testing.T) {
+	repo, _ := novoRepositorio(t)
+	registros := gerar(t, "2025-01-01", 6, "aprovado", "", "reprovado")
+	registros[5].Nome = "100%_especial"
+	popular(t, repo, true, registros, gerar(t, "2025-01-02", 2, "aprovado"))
+
+	casos := []struct {
+		nome   string
+		filtro models.FiltroConsulta
+		ids    []int
+	}{
+		{"por data", models.FiltroConsulta{Data: "2025-01-02", Limite: 10}, []int{7, 8}},
+		{"status nulo", models.FiltroConsulta{Status: "null", Limite: 10}, []int{2, 5}},
+		{"status", models.FiltroConsulta{Status: "reprovado", Limite: 10}, []int{3, 6}},
+		{"prefixo com curinga escapado", models.FiltroConsulta{PrefixoNome: "100%_", Limite: 10}, []int{6}},
+		{"limite decrescente", models.FiltroConsulta{Decrescente: true, Limite: 2}, []int{8, 7}},
+		{"cursor por id", models.FiltroConsulta{Cursor: &models.Cursor{ID: 6}, Limite: 10}, []int{7, 8}},
+		{
+			"cursor por data_prova",
+			models.FiltroConsulta{Ordem: "data_prova", Decrescente: true, Cursor: &models.Cursor{Valor: "2025-01-02", ID: 7}, Limite: 3},
+			[]int{6, 5, 4},
+		},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			pagina, err := repo.ConsultarConcursos(ctx, c.filtro)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids := []int{}
+			for _, registro := range pagina {
+				ids = append(ids, registro.ID)
+			}
+			if !reflect.DeepEqual(ids, c.ids) {
+				t.Fatalf("ids = %v, esperado %v", ids, c.ids)
+			}
+		})
+	}
+}
+
+func TestConsultarProcessados(t *testing.T) {
+	repo, _ := novoRepositorio(t)
+	if err := repo.InserirProcessados(ctx, "lote-a", gerar(t, "2025-01-01", 3, "aprovado"), 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.InserirProcessados(ctx, "lote-b", gerar(t, "2025-01-01", 2, "reprovado"), 1, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	pagina, err := repo.ConsultarProcessados(ctx, models.FiltroConsulta{Lote: "lote-b", Limite: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pagina) != 2 {
+		t.Fatalf("%d registros do lote-b, esperado 2", len(pagina))
+	}
+	for _, registro := range pagina {
+		if registro.Lote.String != "lote-b" || registro.Status.String != "reprovado" {
+			t.Fatalf("registro inesperado: %+v", registro)
+		}
+	}
+}
+
+func TestRegistrarRejeitadosSubstituiOLote(t *testing.T) {
+	repo, db := novoRepositorio(t)
+	motivo := strings.Repeat("é", tamanhoMotivo+10)
+
+	for i := 0; i < 2; i++ {
+		if err := repo.RegistrarRejeitados(ctx, "2025-01-01", "lote-1", motivo, gerar(t, "2025-01-01", 3, "pendente", "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.RegistrarRejeitados(ctx, "2025-01-01", "lote-2", "outro", gerar(t, "2025-01-01", 1, "pendente")); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := contar(t, db, "SELECT COUNT(*) FROM concurso_rejeitado WHERE lote = ?", "lote-1"); n != 3 {
+		t.Fatalf("lote-1 tem %d rejeitados, esperado 3 (consumir de novo não duplica)", n)
+	}
+	if n := contar(t, db, "SELECT COUNT(*) FROM concurso_rejeitado"); n != 4 {
+		t.Fatalf("%d rejeitados no total, esperado 4", n)
+	}
+	if n := contar(t, db, "SELECT MAX(LENGTH(motivo)) FROM concurso_rejeitado"); n != tamanhoMotivo {
+		t.Fatalf("motivo com %d caracteres, esperado %d", n, tamanhoMotivo)
+	}
+}
+
+func TestReconciliar(t *testing.T) {
+	repo, _ := novoRepositorio(t)
+	popular(t, repo, true, gerar(t, "2025-01-01", 5, "aprovado"))
+
+	// ids 1 e 2 processados, 3 rejeitado, 4 e 5 faltando; 99 não existe na origem
+	processados := gerar(t, "2025-01-01", 2, "aprovado")
+	extra := models.Concurso{ID: 99, Nome: "fantasma", Status: status("aprovado"), DataProva: data(t, "2025-01-01")}
+	if err := repo.InserirProcessados(ctx, "lote-1", append(processados, extra), 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	rejeitado := gerar(t, "2025-01-01", 3, "pendente")[2:]
+	if err := repo.RegistrarRejeitados(ctx, "2025-01-01", "lote-1", "status inválido", rejeitado); err != nil {
+		t.Fatal(err)
+	}
+	for _, execucao := range []models.ExecucaoPipeline{
+		{Tipo: models.ExecucaoExtracao, Data: "2025-01-01", Lote: "lote-0", TraceID: "t0", Total: 4, Status: "sucesso"},
+		{Tipo: models.ExecucaoExtracao, Data: "2025-01-01", Lote: "lote-1", TraceID: "t1", Total: 5, Status: "sucesso"},
+		{Tipo: models.ExecucaoConsumo, Data: "2025-01-01", Lote: "lote-1", TraceID: "t2", Total: 4, Status: "parcial", Motivo: "1 rejeitado", Principal: "operador"},
+	} {
+		if err := repo.RegistrarExecucao(ctx, execucao); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resultado, err := repo.Reconciliar(ctx, "2025-01-01", "2025-01-31", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resultado) != 1 {
+		t.Fatalf("%d datas, esperado 1: %+v", len(resultado), resultado)
+	}
+	r := resultado[0]
+	if r.Data != "2025-01-01" || r.TotalOrigem != 5 || r.TotalProcessado != 3 || r.TotalRejeitado != 1 {
+		t.Fatalf("contagens = %+v", r)
+	}
+	if r.TotalFaltando != 2 || !reflect.DeepEqual(r.IDsFaltando, []int{4, 5}) {
+		t.Fatalf("faltando = %d %v", r.TotalFaltando, r.IDsFaltando)
+	}
+	if r.TotalExtras != 1 || !reflect.DeepEqual(r.IDsExtras, []int{99}) {
+		t.Fatalf("extras = %d %v", r.TotalExtras, r.IDsExtras)
+	}
+	if r.UltimaExtracao == nil || r.UltimaExtracao.Lote != "lote-1" || r.TotalExtraido != 5 {
+		t.Fatalf("última extração = %+v", r.UltimaExtracao)
+	}
+	if r.UltimoConsumo == nil || r.UltimoConsumo.Motivo != "1 rejeitado" || r.UltimoConsumo.Principal != "operador" || r.TotalConsumido != 4 {
+		t.Fatalf("último consumo = %+v", r.UltimoConsumo)
+	}
+}
+
+func TestContarDuplicados(t *testing.T) {
+	repo, _ := novoRepositorio(t)
+	// O mesmo lote consumido duas vezes duplica os ids 1 e 2
+	for i := 0; i < 2; i++ {
+		if err := repo.InserirProcessados(ctx, "lote-1", gerar(t, "2025-01-01", 2, "aprovado"), 1, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.InserirProcessados(ctx, "lote-2", gerar(t, "2025-01-02", 3, "aprovado"), 1, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	duplicados, err := repo.ContarDuplicados(ctx, "2025-01-01", "2025-01-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(duplicados, map[string]int{"2025-01-01": 2}) {
+		t.Fatalf("duplicados = %v", duplicados)
+	}
+}
+
+func TestContarPorStatus(t *testing.T) {
+	repo, _ := novoRepositorio(t)
+	if err := repo.InserirProcessados(ctx, "lote-1", gerar(t, "2025-01-01", 3, "aprovado", "reprovado", "aprovado"), 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.InserirProcessados(ctx, "lote-2", gerar(t, "2025-02-01", 1, "aprovado"), 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RegistrarRejeitados(ctx, "2025-01-01", "lote-1", "status inválido", gerar(t, "2025-01-01", 2, "", "pendente")); err != nil {
+		t.Fatal(err)
+	}
+
+	processados, err := repo.ContarProcessadosPorStatus(ctx, "2025-01-01", "2025-01-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperado := []models.ContagemStatus{
+		{Data: "2025-01-01", Status: "aprovado", Total: 2},
+		{Data: "2025-01-01", Status: "reprovado", Total: 1},
+	}
+	if !reflect.DeepEqual(processados, esperado) {
+		t.Fatalf("processados = %+v", processados)
+	}
+
+	rejeitados, err := repo.ContarRejeitadosPorStatus(ctx, "2025-01-01", "2025-01-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperado = []models.ContagemStatus{
+		{Data: "2025-01-01", Nulo: true, Total: 1},
+		{Data: "2025-01-01", Status: "pendente", Total: 1},
+	}
+	if !reflect.DeepEqual(rejeitados, esperado) {
+		t.Fatalf("rejeitados = %+v", rejeitados)
+	}
+}
+
+// escreverTSV alimenta o LOAD DATA do MySQL; as colunas seguem opcoes.colunas
+func TestEscreverTSV(t *testing.T) {
+	registros := []models.Concurso{
+		{ID: 7, Nome: "Ana\tMaria\\", Status: status("aprovado"), DataProva: data(t, "2025-01-01")},
+		{ID: 8, Nome: "Linha\nquebrada", DataProva: data(t, "2025-01-02")},
+	}
+	opcoes := opcoesInsercao{fixas: []colunaFixa{{"carga", "abc"}, {"lote", "l\t1"}}, colunaID: "concurso_id"}
+
+	var buf bytes.Buffer
+	if err := escreverTSV(&buf, registros, opcoes); err != nil {
+		t.Fatal(err)
+	}
+	esperado := "Ana\\tMaria\\\\\taprovado\t2025-01-01\t7\tabc\tl\\t1\n" +
+		"Linha\\nquebrada\t\\N\t2025-01-02\t8\tabc\tl\\t1\n"
+	if buf.String() != esperado {
+		t.Fatalf("TSV =\n%q\nesperado\n%q", buf.String(), esperado)
+	}
+	if colunas := opcoes.colunas(); !reflect.DeepEqual(colunas, []string{"nome", "status", "data_prova", "concurso_id", "carga", "lote"}) {
+		t.Fatalf("colunas = %v", colunas)
+	}
+}
@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"concurso-go-app/internal/database"
+	"concurso-go-app/internal/metricas"
+	"concurso-go-app/internal/models"
+	"concurso-go-app/internal/rastreamento"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// FormatoData é como data_prova é gravada e comparada em todos os bancos. No
+// SQLite a coluna é texto, então gravar sempre neste formato mantém o filtro
+// por igualdade funcionando.
+const FormatoData = "2006-01-02"
+
+// repositorioSQL tem a implementação comum aos bancos. Diferenças de dialeto
+// ficam em placeholder (? ou $n) e nos limites do INSERT multi-row.
+type repositorioSQL struct {
+	db              *sql.DB
+	placeholder     func(query string) string
+	linhasPorInsert int
+	maxParametros   int // 0 = sem limite além de linhasPorInsert
+	// emMassa grava registros pela carga em massa do banco (LOAD DATA, COPY);
+	// nil se o banco não tiver. Retorna ErrCargaEmMassaIndisponivel se o
+	// servidor recusar.
+	emMassa func(ctx context.Context, tabela string, registros []models.Concurso, opcoes opcoesInsercao) error
+}
+
+// executor é o que *sql.DB e *sql.Tx têm em comum
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func (r *repositorioSQL) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+func (r *repositorioSQL) ContarPorData(ctx context.Context, data string) (int, error) {
+	var total int
+	err := database.ComRetry(ctx, func() error {
+		return r.db.QueryRowContext(ctx, r.placeholder(`
+			SELECT COUNT(*)
+			FROM concurso
+			WHERE data_prova = ?
+		`), data).Scan(&total)
+	})
+	return total, err
+}
+
+func (r *repositorioSQL) BuscarPorData(ctx context.Context, data string, limite int, offset int) ([]models.Concurso, error) {
+	var registros []models.Concurso
+	err := database.ComRetry(ctx, func() error {
+		registros = []models.Concurso{}
+		rows, err := r.db.QueryContext(ctx, r.placeholder(`
+			SELECT id, nome, status, data_prova
+			FROM concurso
+			WHERE data_prova = ?
+			ORDER BY id
+			LIMIT ? OFFSET ?
+		`), data, limite, offset)
+		if err != nil {
+			return fmt.Errorf("erro ao buscar registros: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var c models.Concurso
+			if err := rows.Scan(&c.ID, &c.Nome, &c.Status, &c.DataProva); err != nil {
+				return fmt.Errorf("erro ao ler registro: %w", err)
+			}
+			registros = append(registros, c)
+		}
+		return rows.Err()
+	})
+	return registros, err
+}
+
+// InserirProcessados divide os registros em lotes e os grava em paralelo na
+// tabela de staging; só quando todos terminam as linhas são movidas para
+// concurso_processado numa única transação. O número de workers é limitado
+// pelo pool de conexões, e o primeiro erro (ou o cancelamento do contexto)
+// interrompe os demais.
+func (r *repositorioSQL) InserirProcessados(ctx context.Context, lote string, registros []models.Concurso, workers int, progresso func(inseridos int)) error {
+	carga := novoIDCarga()
+	// A limpeza da staging roda mesmo com o contexto cancelado
+	defer r.db.ExecContext(context.WithoutCancel(ctx), r.placeholder("DELETE FROM concurso_processado_staging WHERE carga = ?"), carga)
+
+	workers = database.LimitarWorkers(r.db, workers)
+	partes := make(chan []models.Concurso)
+	opcoes := opcoesInsercao{
+		fixas:    []colunaFixa{{"carga", carga}, {"lote", lote}},
+		colunaID: "concurso_id",
+		comRetry: true,
+	}
+	falhou := make(chan struct{})
+	var primeiroErro error
+	var once sync.Once
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for parte := range partes {
+				if err := r.inserirEmLotes(ctx, r.db, "concurso_processado_staging", parte, opcoes); err != nil {
+					once.Do(func() {
+						primeiroErro = err
+						close(falhou)
+					})
+					return
+				}
+				if progresso != nil {
+					progresso(len(parte))
+				}
+			}
+		}()
+	}
+
+distribuir:
+	for inicio := 0; inicio < len(registros); inicio += r.linhasPorInsert {
+		fim := inicio + r.linhasPorInsert
+		if fim > len(registros) {
+			fim = len(registros)
+		}
+		select {
+		case partes <- registros[inicio:fim]:
+		case <-falhou:
+			break distribuir
+		case <-ctx.Done():
+			break distribuir
+		}
+	}
+	close(partes)
+	wg.Wait()
+
+	if primeiroErro != nil {
+		return fmt.Errorf("erro ao inserir na staging: %w", primeiroErro)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.moverProcessados(ctx, carga)
+}
+
+// moverProcessados passa as linhas da carga de concurso_processado_staging
+// para concurso_processado numa transação. O INSERT … SELECT não é
+// idempotente: o retry só pode acontecer em erros com a transação desfeita
+// (ver database.ErroTransitorio), nunca com o COMMIT possivelmente aplicado.
+func (r *repositorioSQL) moverProcessados(ctx context.Context, carga string) error {
+	return database.ComRetry(ctx, func() error {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		if _, err := tx.ExecContext(ctx, r.placeholder(`
+			INSERT INTO concurso_processado (nome, status, data_prova, lote, concurso_id)
+			SELECT nome, status, data_prova, lote, concurso_id FROM concurso_processado_staging WHERE carga = ?
+		`), carga); err != nil {
+			return fmt.Errorf("erro ao mover staging para concurso_processado: %w", err)
+		}
+		return tx.Commit()
+	})
+}
+
+// colunaFixa é uma coluna com o mesmo valor em todas as linhas do INSERT
+type colunaFixa struct {
+	nome  string
+	valor interface{}
+}
+
+// opcoesInsercao descreve as colunas além de nome, status e data_prova
+type opcoesInsercao struct {
+	fixas    []colunaFixa // ex.: carga e lote na staging
+	colunaID string       // coluna que recebe registro.ID (concurso_id, ordem); vazio = nenhuma
+	comRetry bool
+}
+
+// colunas lista as colunas na ordem em que valores as preenche
+func (o opcoesInsercao) colunas() []string {
+	colunas := []string{"nome", "status", "data_prova"}
+	if o.colunaID != "" {
+		colunas = append(colunas, o.colunaID)
+	}
+	for _, fixa := range o.fixas {
+		colunas = append(colunas, fixa.nome)
+	}
+	return colunas
+}
+
+// valores anexa a args os valores do registro, na ordem de colunas
+func (o opcoesInsercao) valores(args []interface{}, registro models.Concurso) []interface{} {
+	args = append(args, registro.Nome, registro.Status, registro.DataProva.Format(FormatoData))
+	if o.colunaID != "" {
+		args = append(args, registro.ID)
+	}
+	for _, fixa := range o.fixas {
+		args = append(args, fixa.valor)
+	}
+	return args
+}
+
+// inserirEmLotes grava os registros com INSERT multi-row, respeitando os
+// limites de linhas e de parâmetros do banco. Cada lote é repetido em erros
+// transitórios se comRetry, o que não vale dentro de transação (o retry
+// precisaria recomeçar a transação inteira).
+func (r *repositorioSQL) inserirEmLotes(ctx context.Context, exec executor, tabela string, registros []models.Concurso, opcoes opcoesInsercao) error {
+	colunas := opcoes.colunas()
+	linha := "(?" + strings.Repeat(", ?", len(colunas)-1) + ")"
+
+	linhasPorInsert := r.linhasPorInsert
+	if r.maxParametros > 0 && linhasPorInsert*len(colunas) > r.maxParametros {
+		linhasPorInsert = r.maxParametros / len(colunas)
+	}
+
+	for inicio := 0; inicio < len(registros); inicio += linhasPorInsert {
+		fim := inicio + linhasPorInsert
+		if fim > len(registros) {
+			fim = len(registros)
+		}
+
+		valores := make([]string, 0, fim-inicio)
+		args := make([]interface{}, 0, (fim-inicio)*len(colunas))
+		for _, registro := range registros[inicio:fim] {
+			valores = append(valores, linha)
+			args = opcoes.valores(args, registro)
+		}
+		query := r.placeholder(fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", tabela, strings.Join(colunas, ", "), strings.Join(valores, ",")))
+
+		inserir := func() error {
+			_, err := exec.ExecContext(ctx, query, args...)
+			return err
+		}
+		_, span := rastreamento.Span(ctx, "sql.insert",
+			attribute.String("db.system", database.Driver),
+			attribute.String("db.sql.table", tabela),
+			attribute.Int("db.linhas", fim-inicio),
+		)
+		var err error
+		inicioLote := time.Now()
+		if opcoes.comRetry {
+			err = database.ComRetry(ctx, inserir)
+		} else {
+			err = inserir()
+		}
+		rastreamento.Finalizar(span, err)
+		if err != nil {
+			return err
+		}
+		metricas.LatenciaLoteBanco.WithLabelValues(tabela).Observe(time.Since(inicioLote).Seconds())
+	}
+	return nil
+}
+
+// novoIDCarga identifica as linhas de uma carga na tabela de staging
+func novoIDCarga() string {
+	b := make([]byte, 16)
+	cryptorand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// semRebind mantém os placeholders "?" (MySQL e SQLite)
+func semRebind(query string) string {
+	return query
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "database/sql"
+
+// RepositorioSQLite limita o INSERT multi-row a 999 parâmetros, o limite de
+// variáveis das builds mais antigas do SQLite
+type RepositorioSQLite struct {
+	repositorioSQL
+}
+
+func NovoRepositorioSQLite(db *sql.DB) *RepositorioSQLite {
+	return &RepositorioSQLite{repositorioSQL{db: db, placeholder: semRebind, linhasPorInsert: 1000, maxParametros: 999}}
+}
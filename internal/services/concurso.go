@@ -1,295 +1,277 @@
 package services
 
 import (
-	"database/sql"
+	"context"
+	cryptorand "crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
-	"math/rand"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime/debug"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"concurso-go-app/internal/autenticacao"
 	"concurso-go-app/internal/database"
+	"concurso-go-app/internal/jobs"
 	"concurso-go-app/internal/kafka"
+	"concurso-go-app/internal/logger"
+	"concurso-go-app/internal/metricas"
 	"concurso-go-app/internal/models"
-)
+	"concurso-go-app/internal/rastreamento"
+	"concurso-go-app/internal/repository"
 
-type ConcursoService struct{}
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
 
-func NewConcursoService() *ConcursoService {
-	return &ConcursoService{}
+type ConcursoService struct {
+	repo repository.ConcursoRepository
+	log  *slog.Logger
 }
 
-// CriarTabelas cria as tabelas concurso e concurso_processado se não existirem
-func (s *ConcursoService) CriarTabelas() error {
-	// Criar tabela concurso
-	_, err := database.DB.Exec(`
-		CREATE TABLE IF NOT EXISTS concurso (
-			id INT AUTO_INCREMENT PRIMARY KEY,
-			nome VARCHAR(255) NOT NULL,
-			status VARCHAR(50),
-			data_prova DATE NOT NULL
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("erro ao criar tabela concurso: %v", err)
-	}
-
-	// Criar tabela concurso_processado
-	_, err = database.DB.Exec(`
-		CREATE TABLE IF NOT EXISTS concurso_processado (
-			id INT AUTO_INCREMENT PRIMARY KEY,
-			nome VARCHAR(255) NOT NULL,
-			status VARCHAR(50) NOT NULL,
-			data_prova DATE NOT NULL
-		)
-	`)
-	if err != nil {
-		return fmt.Errorf("erro ao criar tabela concurso_processado: %v", err)
+// NewConcursoService cria o serviço com o logger informado (nil = slog.Default)
+func NewConcursoService(log *slog.Logger) *ConcursoService {
+	if log == nil {
+		log = slog.Default()
 	}
-
-	return nil
+	return &ConcursoService{repo: repository.Novo(database.DB, database.Driver), log: log}
 }
 
-// PopularDados popula as tabelas com dados de teste
-func (s *ConcursoService) PopularDados() error {
-	// Criar tabelas se não existirem
-	if err := s.CriarTabelas(); err != nil {
-		return fmt.Errorf("erro ao criar tabelas: %v", err)
-	}
-
-	// Limpar tabelas
-	_, err := database.DB.Exec("DELETE FROM concurso")
-	if err != nil {
-		return fmt.Errorf("erro ao limpar tabela concurso: %v", err)
-	}
-
-	// Gerar dados de 01/01/2025 até 31/01/2025
-	dataInicio := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
-	dataFim := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
-
-	// Iniciar transação para melhor performance
-	tx, err := database.DB.Begin()
-	if err != nil {
-		return fmt.Errorf("erro ao iniciar transação: %v", err)
-	}
+// ExtrairRegistros extrai registros por data e envia para Kafka.
+// falhas, se informado, publica o lote com defeitos propositais (ver Falha).
+// Cancelar ctx interrompe a leitura ou o envio e grava status "cancelado".
+func (s *ConcursoService) ExtrairRegistros(ctx context.Context, data string, falhas InjecaoFalhas) (err error) {
+	inicio := time.Now()
+	ctx, span := rastreamento.Span(ctx, "extracao", attribute.String("data", data))
+	defer func() { rastreamento.Finalizar(span, err) }()
+	ctx = logger.ComAtributos(ctx, slog.String(logger.ChaveData, data))
+	var lote, loteArquivo, traceID string
+	totalProcessado := 0
 	defer func() {
-		if err != nil {
-			tx.Rollback()
+		if err == nil || ctx.Err() == nil {
+			return
 		}
-	}()
-
-	contador := 0
-	totalDias := 31
-	diaAtual := 0
-	const BATCH_SIZE = 1000 // Batch de 1000 registros por INSERT
-
-	fmt.Printf("Iniciando população de dados (BATCH INSERT)...\n")
-	fmt.Printf("Período: 01/01/2025 até 31/01/2025\n")
-
-	// Calcular total esperado
-	registrosDia01 := 1000
-	registrosOutrosDias := 161290 // (5MM - 1000) / 30 dias
-	totalEsperado := registrosDia01 + (30 * registrosOutrosDias)
-	fmt.Printf("Total esperado: %d registros\n", totalEsperado)
-
-	for data := dataInicio; !data.After(dataFim); data = data.AddDate(0, 0, 1) {
-		diaAtual++
-
-		var registrosPorDia int
-		if data.Day() == 1 {
-			registrosPorDia = 1000 // Dia 01: 1000 registros
-			fmt.Printf("Processando dia %d/%d: %s (%d registros - 900 aprovados + 100 NULL)\n",
-				diaAtual, totalDias, data.Format("2006-01-02"), registrosPorDia)
-		} else {
-			registrosPorDia = 161290 // Dia 02-31: ~161K registros
-			fmt.Printf("Processando dia %d/%d: %s (%d registros - aprovados/reprovados)\n",
-				diaAtual, totalDias, data.Format("2006-01-02"), registrosPorDia)
-		}
-
-		// Processar em batches usando padrão otimizado
-		const batchSize = 1000
-		insertBase := `INSERT INTO concurso (nome, status, data_prova) VALUES `
-
-		valCount := 0
-		valueStrings := []string{}
-		valueArgs := []interface{}{}
-
-		for i := 1; i <= registrosPorDia; i++ {
-			nome := fmt.Sprintf("Candidato_%d_%s", i, data.Format("2006-01-02"))
-
-			var status sql.NullString
-			if data.Day() == 1 {
-				// Dia 01: 900 aprovados + 100 NULL
-				if i <= 900 {
-					status.String = "aprovado"
-					status.Valid = true
-				} else {
-					status.Valid = false // NULL
-				}
-			} else {
-				// Dia 02-31: 70% aprovado + 30% reprovado
-				status.Valid = true
-				if rand.Float32() < 0.7 {
-					status.String = "aprovado"
-				} else {
-					status.String = "reprovado"
-				}
-			}
-
-			valueStrings = append(valueStrings, "(?, ?, ?)")
-
-			valueArgs = append(valueArgs, nome, status, data.Format("2006-01-02"))
-			valCount += 3
-
-			if i%batchSize == 0 || i == registrosPorDia {
-				query := insertBase + strings.Join(valueStrings, ",")
-				_, err := tx.Exec(query, valueArgs...)
-				if err != nil {
-					return fmt.Errorf("erro ao inserir batch: %v", err)
-				}
-
-				contador += len(valueStrings)
-				fmt.Printf("  Progresso: %d registros inseridos (batch %d-%d)\n", contador, i-len(valueStrings)+1, i)
-
-				// Limpar arrays para reutilizar
-				valueStrings = []string{}
-				valueArgs = []interface{}{}
-				valCount = 0
-			}
+		s.log.WarnContext(ctx, "extração cancelada", "enviados", totalProcessado)
+		if loteArquivo == "" {
+			loteArquivo = fmt.Sprintf("concurso%s", time.Now().Format("02012006_150405"))
 		}
-	}
-
-	// Commit da transação
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("erro ao fazer commit: %v", err)
-	}
-
-	log.Printf("Populadas %d registros com sucesso (OTIMIZADO)", contador)
-	return nil
-}
+		if logErr := s.gerarLogExtracao(ctx, data, loteArquivo, traceID, totalProcessado, time.Since(inicio), falhas, "cancelado"); logErr != nil {
+			s.log.WarnContext(ctx, "erro ao gerar log de extração", "erro", logErr)
+		}
+		s.registrarExecucao(ctx, models.ExecucaoPipeline{Tipo: models.ExecucaoExtracao, Data: data, Lote: lote, TraceID: traceID, Total: totalProcessado, Status: "cancelado", Motivo: ctx.Err().Error()})
+	}()
 
-// ExtrairRegistros extrai registros por data e envia para Kafka
-func (s *ConcursoService) ExtrairRegistros(data string) error {
-	inicio := time.Now()
-	// Criar tabelas se não existirem
-	if err := s.CriarTabelas(); err != nil {
+	// Verificar se o banco está no ar
+	if err := s.repo.Ping(ctx); err != nil {
+		erro := erroBanco("verificar_banco", "erro ao conectar ao banco", fmt.Errorf("%w: %w", ErrBancoIndisponivel, err))
 		// Log de erro detalhado para banco
-		if logErr := s.gerarLogErroDetalhado(data, "BANCO", "O banco de dados está fora do ar! Sua Integração foi abortada", err, map[string]string{"operacao": "criar_tabelas", "data": data}); logErr != nil {
-			fmt.Printf("⚠️  Erro ao gerar log de erro: %v\n", logErr)
+		if logErr := s.gerarLogErroDetalhado(ctx, data, "O banco de dados está fora do ar! Sua Integração foi abortada", erro, map[string]string{"operacao": "verificar_banco", "data": data}); logErr != nil {
+			s.log.WarnContext(ctx, "erro ao gerar log de erro", "erro", logErr)
 		}
-		return fmt.Errorf("erro ao criar tabelas: %v", err)
+		return erro
 	}
 
 	// Buscar total de registros primeiro
-	var totalRegistros int
-	err := database.DB.QueryRow(`
-		SELECT COUNT(*) 
-		FROM concurso 
-		WHERE DATE(data_prova) = ?
-	`, data).Scan(&totalRegistros)
+	totalRegistros, err := s.repo.ContarPorData(ctx, data)
 	if err != nil {
-		return fmt.Errorf("erro ao contar registros: %v", err)
+		return erroBanco("contar_registros", "erro ao contar registros", err)
 	}
 
 	if totalRegistros == 0 {
-		return fmt.Errorf("nenhum registro encontrado para a data %s", data)
+		return fmt.Errorf("%w para a data %s", ErrSemRegistros, data)
 	}
 
-	fmt.Printf("📊 Encontrados %d registros para extração\n", totalRegistros)
+	s.evento(ctx, jobs.EventoFase, "registros encontrados para extração", "fase", "leitura_banco", "total", totalRegistros)
 
 	// Extrair em batches para não sobrecarregar memória
 	const BATCH_SIZE = 10000
 	var registros []models.Concurso
+	inicioLeitura := time.Now()
+	progressoLeitura := logger.NovoProgresso(s.log, "registros extraídos do banco")
 
 	for offset := 0; offset < totalRegistros; offset += BATCH_SIZE {
-		rows, err := database.DB.Query(`
-			SELECT id, nome, status, data_prova 
-			FROM concurso 
-			WHERE DATE(data_prova) = ?
-			LIMIT ? OFFSET ?
-		`, data, BATCH_SIZE, offset)
+		ctxPagina, spanPagina := rastreamento.Span(ctx, "sql.buscar_pagina", attribute.Int("offset", offset), attribute.Int("limite", BATCH_SIZE))
+		batchRegistros, err := s.repo.BuscarPorData(ctxPagina, data, BATCH_SIZE, offset)
+		rastreamento.Finalizar(spanPagina, err)
 		if err != nil {
-			return fmt.Errorf("erro ao buscar registros: %v", err)
-		}
-
-		batchRegistros := []models.Concurso{}
-		for rows.Next() {
-			var c models.Concurso
-			err := rows.Scan(&c.ID, &c.Nome, &c.Status, &c.DataProva)
-			if err != nil {
-				rows.Close()
-				return fmt.Errorf("erro ao ler registro: %v", err)
-			}
-			batchRegistros = append(batchRegistros, c)
+			return erroBanco("buscar_registros", "erro ao buscar registros", err)
 		}
-		rows.Close()
 
 		registros = append(registros, batchRegistros...)
-		fmt.Printf("  Extraídos: %d/%d registros\n", len(registros), totalRegistros)
+		metricas.RegistrosExtraidos.Add(float64(len(batchRegistros)))
+		progressoLeitura.Registrar(ctx, len(registros), totalRegistros)
 	}
+	metricas.ObservarFase(models.ExecucaoExtracao, "leitura_banco", inicioLeitura)
 
-	// Inicializar produtor Kafka
-	if err := kafka.InitProducer(); err != nil {
+	// Produtor próprio do job: extrações concorrentes não dividem client nem serializador
+	produtor, err := kafka.NovoProdutor()
+	if err != nil {
+		erro := erroKafka("inicializar_produtor", "erro ao inicializar produtor Kafka", err)
 		// Log de erro detalhado para Kafka
-		if logErr := s.gerarLogErroDetalhado(data, "KAFKA", "Erro ao inicializar produtor Kafka", err, map[string]string{"operacao": "inicializar_produtor", "data": data}); logErr != nil {
-			fmt.Printf("⚠️  Erro ao gerar log de erro: %v\n", logErr)
+		if logErr := s.gerarLogErroDetalhado(ctx, data, "Erro ao inicializar produtor Kafka", erro, map[string]string{"operacao": "inicializar_produtor", "data": data}); logErr != nil {
+			s.log.WarnContext(ctx, "erro ao gerar log de erro", "erro", logErr)
 		}
-		return fmt.Errorf("erro ao inicializar produtor Kafka: %v", err)
+		return erro
 	}
+	defer produtor.Close()
 
 	// Enviar header
 	agora := time.Now()
-	lote := fmt.Sprintf("concurso%s", agora.Format("02/01/2006 15:04:05"))
-	loteArquivo := fmt.Sprintf("concurso%s", agora.Format("02012006_150405")) // Para nomes de arquivo
-	topicName := fmt.Sprintf("concurso_%s", data)                             // Tópico específico por data (mantém compatibilidade)
+	lote = fmt.Sprintf("concurso%s", agora.Format("02/01/2006 15:04:05"))
+	ctx = logger.ComAtributos(ctx, slog.String(logger.ChaveLote, lote))
+	loteArquivo = fmt.Sprintf("concurso%s", agora.Format("02012006_150405")) // Para nomes de arquivo
+	topicName := fmt.Sprintf("concurso_%s", data)                            // Tópico específico por data (mantém compatibilidade)
+
+	// Garantir que o tópico exista com o número de partições configurado
+	if err := kafka.GarantirTopico(topicName); err != nil {
+		erro := erroKafka("criar_topico", "erro ao criar tópico", err)
+		if logErr := s.gerarLogErroDetalhado(ctx, data, "Erro ao criar tópico no Kafka", erro, map[string]string{"operacao": "criar_topico", "data": data, "topico": topicName}); logErr != nil {
+			s.log.WarnContext(ctx, "erro ao gerar log de erro", "erro", logErr)
+		}
+		return erro
+	}
+
+	particoes, err := produtor.Partitions(topicName)
+	if err != nil {
+		return erroKafka("listar_particoes", "erro ao listar partições do tópico", err)
+	}
+
+	// Metadados enviados como headers, para rotear/filtrar sem decodificar o corpo
+	// Com tracing ativo o trace_id dos headers e logs é o mesmo do OpenTelemetry
+	traceID = rastreamento.TraceID(ctx)
+	if traceID == "" {
+		traceID = gerarTraceID()
+	}
+	span.SetAttributes(attribute.String("lote", lote), attribute.String("kafka.topico", topicName))
+	metaHeader := kafka.Metadados{Lote: lote, Tipo: kafka.TipoHeader, Data: data, TraceID: traceID}
+	metaRegistro := kafka.Metadados{Lote: lote, Tipo: kafka.TipoRegistro, Data: data, TraceID: traceID}
+	metaFooter := kafka.Metadados{Lote: lote, Tipo: kafka.TipoFooter, Data: data, TraceID: traceID}
 
 	header := models.KafkaHeader{
-		Lote:          lote,
-		TotalEsperado: len(registros),
-		InicioEnvio:   data, // Só a data, sem timestamp
+		Lote:           lote,
+		TotalEsperado:  len(registros),
+		InicioEnvio:    data, // Só a data, sem timestamp
+		TotalParticoes: len(particoes),
 	}
 
-	if err := kafka.SendMessage(topicName, header); err != nil {
-		// Log de erro detalhado para Kafka
-		if logErr := s.gerarLogErroDetalhado(data, "KAFKA", "Erro ao enviar header para Kafka", err, map[string]interface{}{"operacao": "enviar_header", "data": data, "header": header}); logErr != nil {
-			fmt.Printf("⚠️  Erro ao gerar log de erro: %v\n", logErr)
-		}
-		return fmt.Errorf("erro ao enviar header: %v", err)
+	if len(falhas) > 0 {
+		s.log.WarnContext(ctx, "injetando falhas no lote", "falhas", strings.Join(falhas.Lista(), ","))
 	}
 
 	// Enviar registros em batches para Kafka
-	totalProcessado := 0
+	enviadosPorParticao := make(map[int32]int)
 	registrosParaEnviar := len(registros)
+	campoChave := campoChaveMensagem()
 	const KAFKA_BATCH_SIZE = 10000 // Aumentado para 10K por batch
 
-	fmt.Printf("Enviando %d registros para Kafka (BATCH)...\n", registrosParaEnviar)
+	// enviarRegistro publica registros[j], aplicando as falhas por registro
+	// (as primeiras posições do lote são as afetadas)
+	nAfetados := afetados(registrosParaEnviar)
+	enviarRegistro := func(ctx context.Context, j int) error {
+		mensagem := models.NovaConcursoMensagem(registros[j])
+		chave := chaveMensagem(campoChave, registros[j])
+		meta := metaRegistro
+		if falhas.Ativa(FalhaLoteMisto) && j < nAfetados {
+			meta.Lote = lote + "_misto"
+		}
+
+		var particao int32
+		var err error
+		if falhas.Ativa(FalhaJSONMalformado) && j < nAfetados {
+			particao, err = produtor.SendRawMessage(ctx, topicName, chave, meta, []byte(fmt.Sprintf(`{"id": %d, "nome": "%s", "status"`, mensagem.ID, mensagem.Nome)))
+		} else {
+			particao, err = produtor.SendMessage(ctx, topicName, chave, meta, mensagem)
+		}
+		if err != nil {
+			erro := erroKafka("enviar_registro", "erro ao enviar registro", err)
+			// Log de erro detalhado para Kafka
+			if logErr := s.gerarLogErroDetalhado(ctx, data, "Erro ao enviar registro para Kafka", erro, map[string]interface{}{"operacao": "enviar_registro", "data": data, "registro": mensagem, "total_processado": totalProcessado}); logErr != nil {
+				s.log.WarnContext(ctx, "erro ao gerar log de erro", "erro", logErr)
+			}
+			return erro
+		}
+		enviadosPorParticao[particao]++
+		totalProcessado++
+		metricas.RegistrosEnviados.Inc()
+		return nil
+	}
+
+	// Reordenar: o primeiro registro sai antes dos headers e o último depois
+	// dos footers (mas contado neles, como se tivesse chegado atrasado)
+	primeiro, ultimo := 0, registrosParaEnviar
+	if falhas.Ativa(FalhaReordenar) && registrosParaEnviar >= 2 {
+		if err := enviarRegistro(ctx, 0); err != nil {
+			return err
+		}
+		primeiro, ultimo = 1, registrosParaEnviar-1
+		// O último vai para a primeira partição só depois dos footers
+		enviadosPorParticao[particoes[0]]++
+		totalProcessado++
+	}
+
+	// Header vai para todas as partições, para cada uma validar seu trecho do lote
+	for _, particao := range particoes {
+		if falhas.Ativa(FalhaSemHeader) {
+			break
+		}
+		headerParticao := header
+		headerParticao.Particao = particao
+		ctxHeader, spanHeader := rastreamento.Span(ctx, "kafka.enviar_header", attribute.Int("kafka.particao", int(particao)))
+		err := produtor.SendMessageToPartition(ctxHeader, topicName, particao, metaHeader, headerParticao)
+		rastreamento.Finalizar(spanHeader, err)
+		if err != nil {
+			erro := erroKafka("enviar_header", "erro ao enviar header", err)
+			// Log de erro detalhado para Kafka
+			if logErr := s.gerarLogErroDetalhado(ctx, data, "Erro ao enviar header para Kafka", erro, map[string]interface{}{"operacao": "enviar_header", "data": data, "header": headerParticao}); logErr != nil {
+				s.log.WarnContext(ctx, "erro ao gerar log de erro", "erro", logErr)
+			}
+			return erro
+		}
+	}
+
+	s.evento(ctx, jobs.EventoFase, "enviando registros para o Kafka", "fase", "envio_kafka", "total", registrosParaEnviar, "particoes", len(particoes), "chave", campoChave)
+	progressoEnvio := logger.NovoProgresso(s.log, "registros enviados ao Kafka")
+	inicioEnvio := time.Now()
 
 	// Enviar em batches para melhor performance
-	for i := 0; i < registrosParaEnviar; i += KAFKA_BATCH_SIZE {
+	for i := primeiro; i < ultimo; i += KAFKA_BATCH_SIZE {
 		end := i + KAFKA_BATCH_SIZE
-		if end > registrosParaEnviar {
-			end = registrosParaEnviar
+		if end > ultimo {
+			end = ultimo
 		}
 
-		// Enviar batch atual
+		// Enviar batch atual (um span por batch; cada mensagem leva o contexto dele)
+		ctxBatch, spanBatch := rastreamento.Span(ctx, "kafka.enviar_lote", attribute.Int("inicio", i+1), attribute.Int("fim", end))
 		for j := i; j < end; j++ {
-			if err := kafka.SendMessage(topicName, registros[j]); err != nil {
-				// Log de erro detalhado para Kafka
-				if logErr := s.gerarLogErroDetalhado(data, "KAFKA", "Erro ao enviar registro para Kafka", err, map[string]interface{}{"operacao": "enviar_registro", "data": data, "registro": registros[j], "total_processado": totalProcessado}); logErr != nil {
-					fmt.Printf("⚠️  Erro ao gerar log de erro: %v\n", logErr)
-				}
-				return fmt.Errorf("erro ao enviar registro: %v", err)
+			if err := enviarRegistro(ctxBatch, j); err != nil {
+				rastreamento.Finalizar(spanBatch, err)
+				return err
 			}
-			totalProcessado++
 		}
+		rastreamento.Finalizar(spanBatch, nil)
 
-		// Log a cada batch
-		fmt.Printf("  Enviados: %d/%d registros (batch %d-%d)\n", totalProcessado, registrosParaEnviar, i+1, end)
+		s.log.DebugContext(ctx, "batch enviado", "inicio", i+1, "fim", end)
+		progressoEnvio.Registrar(ctx, totalProcessado, registrosParaEnviar)
+	}
+	metricas.ObservarFase(models.ExecucaoExtracao, "envio_kafka", inicioEnvio)
+
+	// Duplicar: reenvio dos primeiros registros, fora da contagem dos footers
+	if falhas.Ativa(FalhaDuplicar) {
+		for j := 0; j < nAfetados; j++ {
+			registro := registros[j]
+			if _, err := produtor.SendMessage(ctx, topicName, chaveMensagem(campoChave, registro), metaRegistro, models.NovaConcursoMensagem(registro)); err != nil {
+				return erroKafka("duplicar_registro", "erro ao duplicar registro", err)
+			}
+		}
 	}
 
 	// Enviar footer
@@ -298,108 +280,188 @@ func (s *ConcursoService) ExtrairRegistros(data string) error {
 		TotalProcessado: totalProcessado,
 		FimEnvio:        data, // Só a data, sem timestamp
 	}
+	if falhas.Ativa(FalhaContagemErrada) {
+		footer.TotalProcessado++
+	}
 
-	if err := kafka.SendMessage(topicName, footer); err != nil {
-		// Log de erro detalhado para Kafka
-		if logErr := s.gerarLogErroDetalhado(data, "KAFKA", "Erro ao enviar footer para Kafka", err, map[string]interface{}{"operacao": "enviar_footer", "data": data, "footer": footer}); logErr != nil {
-			fmt.Printf("⚠️  Erro ao gerar log de erro: %v\n", logErr)
+	// Footer de cada partição leva quantos registros foram gravados nela
+	for i, particao := range particoes {
+		if falhas.Ativa(FalhaSemFooter) {
+			break
+		}
+		footerParticao := footer
+		footerParticao.Particao = particao
+		footerParticao.TotalParticao = enviadosPorParticao[particao]
+		if falhas.Ativa(FalhaContagemErrada) && i == 0 {
+			footerParticao.TotalParticao++
+		}
+		ctxFooter, spanFooter := rastreamento.Span(ctx, "kafka.enviar_footer", attribute.Int("kafka.particao", int(particao)), attribute.Int("total_particao", footerParticao.TotalParticao))
+		err := produtor.SendMessageToPartition(ctxFooter, topicName, particao, metaFooter, footerParticao)
+		rastreamento.Finalizar(spanFooter, err)
+		if err != nil {
+			erro := erroKafka("enviar_footer", "erro ao enviar footer", err)
+			// Log de erro detalhado para Kafka
+			if logErr := s.gerarLogErroDetalhado(ctx, data, "Erro ao enviar footer para Kafka", erro, map[string]interface{}{"operacao": "enviar_footer", "data": data, "footer": footerParticao}); logErr != nil {
+				s.log.WarnContext(ctx, "erro ao gerar log de erro", "erro", logErr)
+			}
+			return erro
+		}
+	}
+
+	if ultimo < registrosParaEnviar {
+		if err := produtor.SendMessageToPartition(ctx, topicName, particoes[0], metaRegistro, models.NovaConcursoMensagem(registros[ultimo])); err != nil {
+			return erroKafka("enviar_registro", "erro ao enviar registro fora de ordem", err)
 		}
-		return fmt.Errorf("erro ao enviar footer: %v", err)
 	}
 
 	// Validar se quantidade enviada bate com quantidade processada
 	if totalProcessado != len(registros) {
-		erroCarga := fmt.Errorf("quantidade enviada (%d) diferente da quantidade processada (%d)", totalProcessado, len(registros))
-		if logErr := s.gerarLogErroDetalhado(data, "KAFKA", "Ocorreram erros na carga", erroCarga, map[string]interface{}{"operacao": "validacao_carga", "data": data, "total_enviado": totalProcessado, "total_registros": len(registros), "header": header, "footer": footer}); logErr != nil {
-			fmt.Printf("⚠️  Erro ao gerar log de erro: %v\n", logErr)
+		erroCarga := &ErroLote{Tipo: ErrContagemDivergente, Data: data, Lote: lote, Motivo: fmt.Sprintf("quantidade enviada (%d) diferente da quantidade processada (%d)", totalProcessado, len(registros))}
+		if logErr := s.gerarLogErroDetalhado(ctx, data, "Ocorreram erros na carga", erroCarga, map[string]interface{}{"operacao": "validacao_carga", "data": data, "total_enviado": totalProcessado, "total_registros": len(registros), "header": header, "footer": footer}); logErr != nil {
+			s.log.WarnContext(ctx, "erro ao gerar log de erro", "erro", logErr)
 		}
-		return fmt.Errorf("erro na carga: %v", erroCarga)
+		return fmt.Errorf("erro na carga: %w", erroCarga)
 	}
 
 	tempoTotal := time.Since(inicio)
+	metricas.ObservarFase(models.ExecucaoExtracao, "total", inicio)
 
 	// Gerar logs
-	if err := s.gerarLogExtracao(data, loteArquivo, len(registros), tempoTotal); err != nil {
-		fmt.Printf("⚠️  Erro ao gerar log de extração: %v\n", err)
+	if err := s.gerarLogExtracao(ctx, data, loteArquivo, traceID, len(registros), tempoTotal, falhas, "sucesso"); err != nil {
+		s.log.WarnContext(ctx, "erro ao gerar log de extração", "erro", err)
 	}
 
-	if err := s.gerarLogKafkaCarga(header, footer, tempoTotal); err != nil {
-		fmt.Printf("⚠️  Erro ao gerar log de carga Kafka: %v\n", err)
+	execucao := models.ExecucaoPipeline{Tipo: models.ExecucaoExtracao, Data: data, Lote: lote, TraceID: traceID, Total: header.TotalEsperado, Status: "sucesso"}
+	if len(falhas) > 0 {
+		execucao.Motivo = "falhas injetadas: " + strings.Join(falhas.Lista(), ", ")
 	}
+	s.registrarExecucao(ctx, execucao)
 
-	log.Printf("Enviados %d registros para Kafka (lote: %s)", totalProcessado, lote)
+	if err := s.gerarLogKafkaCarga(ctx, header, footer, enviadosPorParticao, tempoTotal); err != nil {
+		s.log.WarnContext(ctx, "erro ao gerar log de carga Kafka", "erro", err)
+	}
+
+	s.evento(ctx, jobs.EventoFase, "extração concluída", "fase", "concluida", "enviados", totalProcessado, "duracao", s.formatarTempo(tempoTotal))
 	return nil
 }
 
-// ConsumirRegistros consome registros do Kafka e processa
-func (s *ConcursoService) ConsumirRegistros(data string) error {
+// ConsumirRegistros consome registros do Kafka e processa. Cancelar ctx
+// interrompe o consumo ou a inserção (nada é publicado em
+// concurso_processado) e grava status "cancelado".
+func (s *ConcursoService) ConsumirRegistros(ctx context.Context, data string) (err error) {
 	inicio := time.Now()
-	// Criar tabelas se não existirem
-	if err := s.CriarTabelas(); err != nil {
-		return fmt.Errorf("erro ao criar tabelas: %v", err)
+	ctx, span := rastreamento.Span(ctx, "consumo", attribute.String("data", data))
+	defer func() { rastreamento.Finalizar(span, err) }()
+	ctx = logger.ComAtributos(ctx, slog.String(logger.ChaveData, data))
+	var lote, traceID string
+	var totalConsumidos int64
+	agora := time.Now()
+	loteArquivo := fmt.Sprintf("concurso%s", agora.Format("02012006_150405")) // Para nomes de arquivo
+	defer func() {
+		if err == nil || ctx.Err() == nil {
+			return
+		}
+		total := int(atomic.LoadInt64(&totalConsumidos))
+		s.log.WarnContext(ctx, "consumo cancelado", "consumidos", total)
+		if logErr := s.gerarLogConsumo(ctx, data, loteArquivo, traceID, total, time.Since(inicio), "cancelado", nil); logErr != nil {
+			s.log.WarnContext(ctx, "erro ao gerar log de consumo", "erro", logErr)
+		}
+		s.registrarExecucao(ctx, models.ExecucaoPipeline{Tipo: models.ExecucaoConsumo, Data: data, Lote: lote, TraceID: traceID, Total: total, Status: "cancelado", Motivo: ctx.Err().Error()})
+	}()
+
+	// Consumidor próprio do job: fechá-lo não afeta outros consumos em andamento
+	consumidor, err := kafka.NovoConsumidor()
+	if err != nil {
+		return erroKafka("inicializar_consumidor", "erro ao inicializar consumidor Kafka", err)
 	}
+	defer consumidor.Close()
+
+	// Cada partição é consumida em paralelo e tem seu próprio header/footer
+	var mu sync.Mutex
+	estados := make(map[int32]*estadoParticao)
 
-	// Inicializar consumidor Kafka
-	if err := kafka.InitConsumer(); err != nil {
-		return fmt.Errorf("erro ao inicializar consumidor Kafka: %v", err)
+	estadoDa := func(particao int32) *estadoParticao {
+		mu.Lock()
+		defer mu.Unlock()
+		estado, ok := estados[particao]
+		if !ok {
+			estado = &estadoParticao{}
+			estados[particao] = estado
+		}
+		return estado
 	}
-	defer kafka.CloseConsumer()
 
-	agora := time.Now()
-	loteArquivo := fmt.Sprintf("concurso%s", agora.Format("02012006_150405")) // Para nomes de arquivo
-	var header *models.KafkaHeader
-	var footer *models.KafkaFooter
-	var lote string // Será definido quando encontrar o header
-	var registros []models.Concurso
-	var registrosValidos []models.Concurso
-	var registrosComErro bool = false // Marca se há erro no lote
-
-	// Handler para processar mensagens - OTIMIZADO COM DEBUG
-	handler := func(message []byte) bool {
-		// Tentar header
-		var headerMsg models.KafkaHeader
-		if err := json.Unmarshal(message, &headerMsg); err == nil {
-			if headerMsg.TotalEsperado > 0 {
-				header = &headerMsg
-				lote = headerMsg.Lote // Usar o lote do header
-				fmt.Printf("📋 Header encontrado: %d registros esperados\n", header.TotalEsperado)
-				return false
-			}
+	// O processamento do lote continua o trace da extração, lido do primeiro
+	// header (o span do consumo fica como link)
+	var continuarTrace sync.Once
+	var spanLote trace.Span
+	ctxLote := ctx
+	defer func() {
+		if spanLote != nil {
+			rastreamento.Finalizar(spanLote, err)
 		}
+	}()
 
-		// Tentar footer
-		var footerMsg models.KafkaFooter
-		if err := json.Unmarshal(message, &footerMsg); err == nil {
-			if footerMsg.Lote == lote && footerMsg.TotalProcessado > 0 {
-				footer = &footerMsg
-				fmt.Printf("📋 Footer encontrado: %d registros processados\n", footer.TotalProcessado)
-				return true // PARAR IMEDIATAMENTE
-			}
+	// Handler para processar mensagens - chamado em paralelo, uma goroutine por partição
+	inicioConsumo := time.Now()
+	progressoConsumo := logger.NovoProgresso(s.log, "registros consumidos do Kafka")
+	handler := func(message kafka.Mensagem) bool {
+		estado := estadoDa(message.Particao)
+		meta := message.Metadados()
+
+		// Mensagens antigas não têm o header "tipo": descobrir pelo corpo
+		tipo := meta.Tipo
+		if tipo == "" {
+			tipo = tipoMensagemLegado(message.Valor)
 		}
 
-		// Tentar registro
-		var registro models.Concurso
-		if err := json.Unmarshal(message, &registro); err == nil {
-			registros = append(registros, registro)
+		switch tipo {
+		case kafka.TipoHeader:
+			var headerMsg models.KafkaHeader
+			if err := kafka.Desserializar(message.Valor, &headerMsg); err == nil && headerMsg.TotalEsperado > 0 {
+				estado.header = &headerMsg
+				estado.traceID = meta.TraceID
+				continuarTrace.Do(func() {
+					ctxLote, spanLote = rastreamento.Continuar(ctx, message.Headers, "consumo.lote", attribute.String("lote", headerMsg.Lote), attribute.String("data", data))
+				})
+				s.evento(ctx, jobs.EventoHeader, "header encontrado", "particao", message.Particao, "esperados", headerMsg.TotalEsperado, logger.ChaveLote, headerMsg.Lote)
+			}
 
-			// Validar status - se inválido, marca para rejeitar todo o lote
-			if !registro.Status.Valid || (registro.Status.String != "aprovado" && registro.Status.String != "reprovado") {
-				// Encontrar registro inválido - marcar para rejeitar todo o lote
-				registrosValidos = nil  // Limpar registros válidos
-				registrosComErro = true // Marcar que há erro no lote
-			} else if !registrosComErro {
-				// Só adiciona se não há erro no lote
-				registrosValidos = append(registrosValidos, registro)
+		case kafka.TipoFooter:
+			var footerMsg models.KafkaFooter
+			if err := kafka.Desserializar(message.Valor, &footerMsg); err == nil {
+				if estado.header != nil && footerMsg.Lote == estado.header.Lote && footerMsg.TotalProcessado > 0 {
+					estado.footer = &footerMsg
+					s.evento(ctx, jobs.EventoFooter, "footer encontrado", "particao", message.Particao, "registros_particao", footerMsg.TotalParticao, logger.ChaveLote, footerMsg.Lote)
+					return true // PARAR IMEDIATAMENTE
+				}
 			}
 
-			// Log de progresso a cada 1000 registros (otimizado)
-			if len(registros)%1000 == 0 {
-				fmt.Printf("  Consumidos: %d registros\n", len(registros))
+		case kafka.TipoRegistro:
+			// Registros de outro lote (identificados pelo header "lote") ficam fora
+			// dos registros, mas contados: o lote é rejeitado como misto
+			if meta.Lote != "" && estado.header != nil && meta.Lote != estado.header.Lote {
+				if estado.outroLote == nil {
+					estado.outroLote = make(map[string]int)
+				}
+				estado.outroLote[meta.Lote]++
+				return false
 			}
 
-			// DEBUG: Log a cada 10K para ver se está progredindo
-			if len(registros)%10000 == 0 {
-				fmt.Printf("🔍 DEBUG: Processados %d registros, continuando...\n", len(registros))
+			// ConcursoMensagem também lê o formato legado (status como objeto, data RFC3339)
+			var registro models.ConcursoMensagem
+			if err := kafka.Desserializar(message.Valor, &registro); err != nil {
+				estado.malformados++
+				s.log.WarnContext(ctx, "mensagem malformada", "particao", message.Particao, "offset", message.Offset, "erro", err)
+			} else {
+				if estado.header == nil {
+					estado.antesDoHeader++
+				}
+				estado.registros = append(estado.registros, registro.Concurso())
+				metricas.RegistrosConsumidos.Inc()
+
+				total := atomic.AddInt64(&totalConsumidos, 1)
+				progressoConsumo.Registrar(ctx, int(total), 0)
 			}
 		}
 
@@ -408,240 +470,250 @@ func (s *ConcursoService) ConsumirRegistros(data string) error {
 
 	// Consumir mensagens do tópico específico da data
 	topicName := fmt.Sprintf("concurso_%s", data)
-	if err := kafka.ConsumeMessages(topicName, handler); err != nil {
-		return fmt.Errorf("erro ao consumir mensagens: %v", err)
+	s.evento(ctx, jobs.EventoFase, "consumindo registros do Kafka", "fase", "consumo_kafka", "topico", topicName)
+	ctxConsumo, spanConsumo := rastreamento.Span(ctx, "kafka.consumir", attribute.String("kafka.topico", topicName))
+	err = consumidor.ConsumeMessages(ctxConsumo, topicName, handler)
+	spanConsumo.SetAttributes(attribute.Int64("registros", atomic.LoadInt64(&totalConsumidos)))
+	rastreamento.Finalizar(spanConsumo, err)
+	if err != nil {
+		return erroKafka("consumir_mensagens", "erro ao consumir mensagens", err)
 	}
+	metricas.ObservarFase(models.ExecucaoConsumo, "consumo_kafka", inicioConsumo)
 
-	// Validações
-	if header == nil {
-		fmt.Printf("❌ ERRO: Header não encontrado\n")
-		// Salvar registros para análise
-		if err := s.gerarLogLoteErro(data, lote, "Header não encontrado", registros); err != nil {
-			fmt.Printf("⚠️  Erro ao gerar log de erro: %v\n", err)
-		}
-
-		// Enviar erro para tópico Kafka e coletar IDs
-		var idsLinhaKafka []string
-		for i, registro := range registros {
-			idLinhaKafka := fmt.Sprintf("%s_%d", lote, i)
-			idsLinhaKafka = append(idsLinhaKafka, idLinhaKafka)
-			if err := s.enviarErroParaKafka(data, idLinhaKafka, registro, "Header não encontrado"); err != nil {
-				fmt.Printf("⚠️  Erro ao enviar para Kafka: %v\n", err)
-			}
+	// Daqui em diante os spans ficam no trace do lote (o cancelamento continua o de ctx)
+	ctx = ctxLote
+
+	// Juntar as partições em ordem
+	particoes := make([]int32, 0, len(estados))
+	for particao := range estados {
+		particoes = append(particoes, particao)
+	}
+	sort.Slice(particoes, func(i, j int) bool { return particoes[i] < particoes[j] })
+
+	// lote e traceID vêm do header da primeira partição
+	var header *models.KafkaHeader
+	var footer *models.KafkaFooter
+	var registros []models.Concurso
+	headerAusente, footerAusente := len(particoes) == 0, len(particoes) == 0
+	var divergencias, mistos []string
+
+	for _, particao := range particoes {
+		estado := estados[particao]
+		registros = append(registros, estado.registros...)
+
+		if estado.malformados > 0 {
+			divergencias = append(divergencias, fmt.Sprintf("partição %d: %d mensagem(ns) malformada(s)", particao, estado.malformados))
+		}
+		if estado.antesDoHeader > 0 {
+			divergencias = append(divergencias, fmt.Sprintf("partição %d: %d registro(s) antes do header", particao, estado.antesDoHeader))
 		}
 
-		// Salvar IDs das linhas Kafka
-		if err := s.salvarIDsLinhaKafka(data, loteArquivo, idsLinhaKafka, "Header não encontrado"); err != nil {
-			fmt.Printf("⚠️  Erro ao salvar IDs: %v\n", err)
+		if estado.header == nil {
+			headerAusente = true
+			continue
 		}
-		return fmt.Errorf("header não encontrado")
-	}
-	if footer == nil {
-		fmt.Printf("❌ ERRO: Footer não encontrado\n")
-		// Salvar registros para análise
-		if err := s.gerarLogLoteErro(data, lote, "Footer não encontrado", registros); err != nil {
-			fmt.Printf("⚠️  Erro ao gerar log de erro: %v\n", err)
+		if header == nil {
+			header = estado.header
+			lote = header.Lote
+			traceID = estado.traceID
+			ctx = logger.ComAtributos(ctx, slog.String(logger.ChaveLote, lote))
+		} else if estado.header.Lote != lote {
+			mistos = append(mistos, fmt.Sprintf("partição %d pertence ao lote %s (esperado %s)", particao, estado.header.Lote, lote))
+		}
+		outros := make([]string, 0, len(estado.outroLote))
+		for outro := range estado.outroLote {
+			outros = append(outros, outro)
+		}
+		sort.Strings(outros)
+		for _, outro := range outros {
+			mistos = append(mistos, fmt.Sprintf("partição %d: %d registro(s) do lote %s", particao, estado.outroLote[outro], outro))
 		}
 
-		// Enviar erro para tópico Kafka e coletar IDs
-		var idsLinhaKafka []string
-		for i, registro := range registros {
-			idLinhaKafka := fmt.Sprintf("%s_%d", lote, i)
-			idsLinhaKafka = append(idsLinhaKafka, idLinhaKafka)
-			if err := s.enviarErroParaKafka(data, idLinhaKafka, registro, "Footer não encontrado"); err != nil {
-				fmt.Printf("⚠️  Erro ao enviar para Kafka: %v\n", err)
-			}
+		if estado.footer == nil {
+			footerAusente = true
+			continue
+		}
+		if footer == nil {
+			footer = estado.footer
 		}
+		if len(estado.registros) != estado.footer.TotalParticao {
+			divergencias = append(divergencias, fmt.Sprintf("partição %d: esperado (%d) diferente do consumido (%d)", particao, estado.footer.TotalParticao, len(estado.registros)))
+		}
+	}
 
-		// Salvar IDs das linhas Kafka
-		if err := s.salvarIDsLinhaKafka(data, loteArquivo, idsLinhaKafka, "Footer não encontrado"); err != nil {
-			fmt.Printf("⚠️  Erro ao salvar IDs: %v\n", err)
+	// Um mesmo id duas vezes indica reenvio (retry do produtor, por exemplo)
+	vistos := make(map[int]bool, len(registros))
+	duplicados := 0
+	for _, registro := range registros {
+		if vistos[registro.ID] {
+			duplicados++
 		}
-		return fmt.Errorf("footer não encontrado")
+		vistos[registro.ID] = true
 	}
-	if header.TotalEsperado != footer.TotalProcessado {
-		fmt.Printf("❌ ERRO: Total esperado (%d) diferente do processado (%d)\n", header.TotalEsperado, footer.TotalProcessado)
-		// Salvar registros para análise
+	if duplicados > 0 {
+		divergencias = append(divergencias, fmt.Sprintf("%d registro(s) duplicado(s)", duplicados))
+	}
+
+	// Validações
+	if headerAusente {
+		s.log.ErrorContext(ctx, "lote rejeitado: header não encontrado", "registros", len(registros))
+		jobs.Publicar(ctx, jobs.EventoRejeicao, "lote rejeitado: header não encontrado", "motivo", rejeicaoHeaderAusente, "registros", len(registros))
+		s.registrarFalhaLote(ctx, data, lote, loteArquivo, traceID, "falha", rejeicaoHeaderAusente, "Header não encontrado", registros)
+		return &ErroLote{Tipo: ErrHeaderAusente, Data: data, Lote: lote}
+	}
+	if footerAusente {
+		s.log.ErrorContext(ctx, "lote rejeitado: footer não encontrado", "registros", len(registros))
+		jobs.Publicar(ctx, jobs.EventoRejeicao, "lote rejeitado: footer não encontrado", "motivo", rejeicaoFooterAusente, "registros", len(registros))
+		s.registrarFalhaLote(ctx, data, lote, loteArquivo, traceID, "falha", rejeicaoFooterAusente, "Footer não encontrado", registros)
+		return &ErroLote{Tipo: ErrFooterAusente, Data: data, Lote: lote}
+	}
+	if len(mistos) > 0 {
+		motivo := "Registros de outro lote: " + strings.Join(mistos, "; ")
+		s.log.ErrorContext(ctx, "lote rejeitado: registros de outro lote", "motivo", motivo)
+		jobs.Publicar(ctx, jobs.EventoRejeicao, "lote rejeitado: registros de outro lote", "motivo", rejeicaoLoteMisto, "detalhe", motivo)
+		s.registrarFalhaLote(ctx, data, lote, loteArquivo, traceID, "falha", rejeicaoLoteMisto, motivo, registros)
+		return &ErroLote{Tipo: ErrLoteMisto, Data: data, Lote: lote, Motivo: motivo}
+	}
+	if header.TotalEsperado != footer.TotalProcessado || header.TotalEsperado != len(registros) || len(divergencias) > 0 {
 		motivo := fmt.Sprintf("Total esperado (%d) diferente do processado (%d)", header.TotalEsperado, footer.TotalProcessado)
-		if err := s.gerarLogLoteErro(data, lote, motivo, registros); err != nil {
-			fmt.Printf("⚠️  Erro ao gerar log de erro: %v\n", err)
+		if header.TotalEsperado == footer.TotalProcessado {
+			motivo = fmt.Sprintf("Total esperado (%d) diferente do consumido (%d)", header.TotalEsperado, len(registros))
 		}
-
-		// Enviar erro para tópico Kafka e coletar IDs
-		var idsLinhaKafka []string
-		for i, registro := range registros {
-			idLinhaKafka := fmt.Sprintf("%s_%d", lote, i)
-			idsLinhaKafka = append(idsLinhaKafka, idLinhaKafka)
-			if err := s.enviarErroParaKafka(data, idLinhaKafka, registro, motivo); err != nil {
-				fmt.Printf("⚠️  Erro ao enviar para Kafka: %v\n", err)
-			}
+		if len(divergencias) > 0 {
+			motivo += ": " + strings.Join(divergencias, "; ")
 		}
+		s.log.ErrorContext(ctx, "lote rejeitado: contagem divergente", "motivo", motivo)
+		jobs.Publicar(ctx, jobs.EventoRejeicao, "lote rejeitado: contagem divergente", "motivo", rejeicaoContagemDivergente, "detalhe", motivo)
+		s.registrarFalhaLote(ctx, data, lote, loteArquivo, traceID, "falha", rejeicaoContagemDivergente, motivo, registros)
+		return &ErroLote{Tipo: ErrContagemDivergente, Data: data, Lote: lote, Motivo: motivo}
+	}
 
-		// Salvar IDs das linhas Kafka
-		if err := s.salvarIDsLinhaKafka(data, loteArquivo, idsLinhaKafka, motivo); err != nil {
-			fmt.Printf("⚠️  Erro ao salvar IDs: %v\n", err)
+	// Validar status - um registro inválido rejeita todo o lote
+	var registrosValidos []models.Concurso
+	for _, registro := range registros {
+		if !registro.Status.Valid || (registro.Status.String != "aprovado" && registro.Status.String != "reprovado") {
+			registrosValidos = nil
+			break
 		}
-		return fmt.Errorf("total esperado (%d) diferente do processado (%d)", header.TotalEsperado, footer.TotalProcessado)
+		registrosValidos = append(registrosValidos, registro)
 	}
 
 	// Inserir registros válidos
 	if len(registrosValidos) > 0 {
-		fmt.Printf("Inserindo %d registros válidos na tabela processada...\n", len(registrosValidos))
+		s.evento(ctx, jobs.EventoFase, "inserindo registros válidos em concurso_processado", "fase", "insercao", "total", len(registrosValidos))
 
-		// Preparar statement
-		stmt, err := database.DB.Prepare(`
-			INSERT INTO concurso_processado (nome, status, data_prova) VALUES (?, ?, ?)
-		`)
+		insercao, err := s.inserirProcessados(ctx, lote, registrosValidos)
 		if err != nil {
-			return fmt.Errorf("erro ao preparar statement: %v", err)
-		}
-		defer stmt.Close()
-
-		// Inserir registros em batch para melhor performance
-		totalInseridos := 0
-		const BATCH_SIZE = 1000
-
-		for i := 0; i < len(registrosValidos); i += BATCH_SIZE {
-			end := i + BATCH_SIZE
-			if end > len(registrosValidos) {
-				end = len(registrosValidos)
-			}
-
-			// Construir batch insert
-			var values []string
-			var args []interface{}
-
-			for j := i; j < end; j++ {
-				registro := registrosValidos[j]
-				values = append(values, "(?, ?, ?)")
-				args = append(args, registro.Nome, registro.Status.String, registro.DataProva.Format("2006-01-02"))
-			}
-
-			// Executar batch insert
-			query := fmt.Sprintf("INSERT INTO concurso_processado (nome, status, data_prova) VALUES %s", strings.Join(values, ","))
-			_, err := database.DB.Exec(query, args...)
-			if err != nil {
-				return fmt.Errorf("erro ao inserir batch: %v", err)
-			}
-
-			totalInseridos += len(values)
-			fmt.Printf("  Inseridos: %d/%d registros válidos (batch %d-%d)\n", totalInseridos, len(registrosValidos), i+1, end)
+			return err
 		}
-
-		fmt.Printf("✅ Processamento concluído: %d registros válidos inseridos de %d total\n", len(registrosValidos), len(registros))
-
-		// Tópico específico por data - não precisa limpar
-		fmt.Printf("✅ Tópico Kafka %s mantido (sem conflitos)\n", topicName)
+		s.log.InfoContext(ctx, "inserção concluída", "metodo", insercao.Metodo, "inseridos", insercao.TotalInserido, "duracao", insercao.TempoInsercao, "registros_por_segundo", int(insercao.RegistrosPorSegundo))
 
 		// Gerar log de consumo (sucesso)
 		tempoTotal := time.Since(inicio)
-		if err := s.gerarLogConsumo(data, loteArquivo, len(registros), tempoTotal, "sucesso"); err != nil {
-			fmt.Printf("⚠️  Erro ao gerar log de consumo: %v\n", err)
+		metricas.ObservarFase(models.ExecucaoConsumo, "total", inicio)
+		if err := s.gerarLogConsumo(ctx, data, loteArquivo, traceID, len(registros), tempoTotal, "sucesso", insercao); err != nil {
+			s.log.WarnContext(ctx, "erro ao gerar log de consumo", "erro", err)
 		}
+		s.registrarExecucao(ctx, models.ExecucaoPipeline{Tipo: models.ExecucaoConsumo, Data: data, Lote: lote, TraceID: traceID, Total: len(registros), Status: "sucesso"})
 
-		fmt.Printf("🎉 CONSUMO CONCLUÍDO COM SUCESSO! %d registros processados em %s\n", len(registrosValidos), s.formatarTempo(tempoTotal))
+		s.evento(ctx, jobs.EventoFase, "consumo concluído", "fase", "concluida", "processados", len(registrosValidos), "duracao", s.formatarTempo(tempoTotal))
 	} else {
-		fmt.Printf("⚠️  Nenhum registro válido encontrado de %d total\n", len(registros))
-
-		// Tópico específico por data - não precisa limpar
-		fmt.Printf("✅ Tópico Kafka %s mantido (sem conflitos)\n", topicName)
+		s.log.WarnContext(ctx, "nenhum registro válido no lote", "total", len(registros))
 
 		// Gerar log de consumo (sem registros válidos)
 		tempoTotal := time.Since(inicio)
-		if err := s.gerarLogConsumo(data, loteArquivo, len(registros), tempoTotal, "sem_registros_validos"); err != nil {
-			fmt.Printf("⚠️  Erro ao gerar log de consumo: %v\n", err)
+		if err := s.gerarLogConsumo(ctx, data, loteArquivo, traceID, len(registros), tempoTotal, "sem_registros_validos", nil); err != nil {
+			s.log.WarnContext(ctx, "erro ao gerar log de consumo", "erro", err)
 		}
 
 		// Salvar TODOS os registros para análise posterior (incluindo os válidos)
 		motivo := "Lote rejeitado - Status inválido encontrado (NULL ou diferente de aprovado/reprovado)"
-		if err := s.gerarLogLoteErro(data, loteArquivo, motivo, registros); err != nil {
-			fmt.Printf("⚠️  Erro ao gerar log de erro: %v\n", err)
-		}
-
-		// Enviar erro para tópico Kafka e coletar IDs
-		var idsLinhaKafka []string
-		for i, registro := range registros {
-			idLinhaKafka := fmt.Sprintf("%s_%d", lote, i)
-			idsLinhaKafka = append(idsLinhaKafka, idLinhaKafka)
-			if err := s.enviarErroParaKafka(data, idLinhaKafka, registro, motivo); err != nil {
-				fmt.Printf("⚠️  Erro ao enviar para Kafka: %v\n", err)
-			}
-		}
-
-		// Salvar IDs das linhas Kafka
-		if err := s.salvarIDsLinhaKafka(data, loteArquivo, idsLinhaKafka, motivo); err != nil {
-			fmt.Printf("⚠️  Erro ao salvar IDs: %v\n", err)
-		}
+		s.registrarFalhaLote(ctx, data, lote, loteArquivo, traceID, "sem_registros_validos", rejeicaoStatusInvalido, motivo, registros)
 
-		fmt.Printf("❌ CONSUMO CONCLUÍDO COM FALHA! Nenhum registro válido encontrado em %s\n", s.formatarTempo(tempoTotal))
-		fmt.Printf("📄 Registros com erro salvos para análise posterior\n")
+		s.log.ErrorContext(ctx, "consumo concluído com falha: lote rejeitado por status inválido", "duracao", s.formatarTempo(tempoTotal))
+		jobs.Publicar(ctx, jobs.EventoRejeicao, "lote rejeitado por status inválido", "motivo", rejeicaoStatusInvalido, "registros", len(registros))
 	}
 
 	return nil
 }
 
-// LimparTopicoKafka limpa o tópico Kafka
-func (s *ConcursoService) LimparTopicoKafka() error {
-	// Abordagem simples: usar um tópico com timestamp para evitar conflitos
-	topicName := fmt.Sprintf("concurso_%d", time.Now().Unix())
-
-	fmt.Printf("🔄 Usando tópico temporário: %s\n", topicName)
-
-	// Criar tópico temporário
-	createCmd := exec.Command("docker", "exec", "concurso_kafka", "kafka-topics",
-		"--bootstrap-server", "localhost:9092",
-		"--create",
-		"--topic", topicName,
-		"--partitions", "1",
-		"--replication-factor", "1")
+// inserirProcessados grava os registros validados em concurso_processado. Com
+// DB_BULK_LOAD=true usa a carga em massa do banco (LOAD DATA no MySQL, COPY no
+// PostgreSQL) e, se o servidor não permitir, volta para os INSERTs em lote,
+// feitos por DB_INSERT_WORKERS workers em paralelo (padrão 4).
+func (s *ConcursoService) inserirProcessados(ctx context.Context, lote string, registros []models.Concurso) (insercao *models.InsercaoLog, err error) {
+	inicio := time.Now()
+	metodo := "insert_lote"
+	ctx, span := rastreamento.Span(ctx, "insercao", attribute.Int("registros", len(registros)))
+	defer func() {
+		span.SetAttributes(attribute.String("metodo", metodo))
+		rastreamento.Finalizar(span, err)
+	}()
 
-	if err := createCmd.Run(); err != nil {
-		return fmt.Errorf("erro ao criar tópico temporário: %v", err)
+	carregador, temCarga := s.repo.(repository.CarregadorEmMassa)
+	usarCarga, _ := strconv.ParseBool(os.Getenv("DB_BULK_LOAD"))
+	carregado := false
+	if usarCarga && temCarga {
+		err := carregador.CarregarProcessados(ctx, lote, registros)
+		switch {
+		case err == nil:
+			metodo = "carga_em_massa"
+			carregado = true
+		case errors.Is(err, repository.ErrCargaEmMassaIndisponivel):
+			s.log.WarnContext(ctx, "carga em massa indisponível, usando INSERT em lote", "erro", err)
+		default:
+			return nil, erroBanco("carga_em_massa", "erro na carga em massa", err)
+		}
 	}
 
-	// Deletar tópico antigo
-	deleteCmd := exec.Command("docker", "exec", "concurso_kafka", "kafka-topics",
-		"--bootstrap-server", "localhost:9092",
-		"--delete",
-		"--topic", "concurso")
+	if !carregado {
+		workers, err := strconv.Atoi(os.Getenv("DB_INSERT_WORKERS"))
+		if err != nil || workers < 1 {
+			workers = 4
+		}
 
-	if err := deleteCmd.Run(); err != nil {
-		fmt.Printf("⚠️  Erro ao deletar tópico antigo: %v\n", err)
+		// Os workers gravam em staging; o repositório repete em deadlock/conexão
+		// perdida e só publica em concurso_processado se todos os lotes entrarem
+		var totalInseridos int64
+		progressoInsercao := logger.NovoProgresso(s.log, "registros inseridos")
+		progresso := func(inseridos int) {
+			total := atomic.AddInt64(&totalInseridos, int64(inseridos))
+			progressoInsercao.Registrar(ctx, int(total), len(registros))
+		}
+		if err := s.repo.InserirProcessados(ctx, lote, registros, workers, progresso); err != nil {
+			return nil, erroBanco("inserir_processados", "erro ao inserir batch", err)
+		}
 	}
 
-	// Aguardar um pouco
-	time.Sleep(2 * time.Second)
+	tempo := time.Since(inicio)
+	metricas.ObservarFase(models.ExecucaoConsumo, "insercao", inicio)
+	metricas.RegistrosInseridos.WithLabelValues(metodo).Add(float64(len(registros)))
+	return &models.InsercaoLog{
+		Metodo:              metodo,
+		TotalInserido:       len(registros),
+		TempoInsercao:       s.formatarTempo(tempo),
+		RegistrosPorSegundo: float64(len(registros)) / tempo.Seconds(),
+	}, nil
+}
 
-	// Recriar tópico original
-	recreateCmd := exec.Command("docker", "exec", "concurso_kafka", "kafka-topics",
-		"--bootstrap-server", "localhost:9092",
-		"--create",
-		"--topic", "concurso",
-		"--partitions", "1",
-		"--replication-factor", "1")
+// LimparTopicoKafka limpa o tópico Kafka apagando e recriando o tópico pelo admin
+func (s *ConcursoService) LimparTopicoKafka(ctx context.Context) error {
+	topicName := "concurso"
 
-	if err := recreateCmd.Run(); err != nil {
-		return fmt.Errorf("erro ao recriar tópico original: %v", err)
-	}
+	s.log.InfoContext(ctx, "recriando tópico", "topico", topicName)
 
-	// Deletar tópico temporário
-	deleteTempCmd := exec.Command("docker", "exec", "concurso_kafka", "kafka-topics",
-		"--bootstrap-server", "localhost:9092",
-		"--delete",
-		"--topic", topicName)
-
-	if err := deleteTempCmd.Run(); err != nil {
-		fmt.Printf("⚠️  Erro ao deletar tópico temporário: %v\n", err)
+	if err := kafka.RecriarTopico(topicName); err != nil {
+		return erroKafka("recriar_topico", "erro ao recriar tópico", err)
 	}
 
-	fmt.Printf("✅ Tópico Kafka limpo com sucesso\n")
+	s.log.InfoContext(ctx, "tópico Kafka limpo", "topico", topicName)
 	return nil
 }
 
 // limparTopicoKafkaAlternativo método alternativo para limpar Kafka
-func (s *ConcursoService) limparTopicoKafkaAlternativo() error {
+func (s *ConcursoService) limparTopicoKafkaAlternativo(ctx context.Context) error {
 	// Resetar offset para o final (efetivamente "limpa" as mensagens antigas)
-	fmt.Printf("🔄 Resetando offset do tópico...\n")
+	s.log.InfoContext(ctx, "resetando offset do tópico")
 
 	// Criar um grupo de consumidor temporário e resetar offset
 	resetCmd := exec.Command("docker", "exec", "concurso_kafka", "kafka-consumer-groups",
@@ -653,11 +725,11 @@ func (s *ConcursoService) limparTopicoKafkaAlternativo() error {
 		"--execute")
 
 	if err := resetCmd.Run(); err != nil {
-		fmt.Printf("⚠️  Erro ao resetar offset: %v\n", err)
+		s.log.WarnContext(ctx, "erro ao resetar offset", "erro", err)
 	}
 
 	// Consumir todas as mensagens antigas
-	fmt.Printf("🧹 Consumindo mensagens antigas...\n")
+	s.log.InfoContext(ctx, "consumindo mensagens antigas")
 
 	consumeCmd := exec.Command("docker", "exec", "concurso_kafka", "kafka-console-consumer",
 		"--bootstrap-server", "localhost:9092",
@@ -668,13 +740,153 @@ func (s *ConcursoService) limparTopicoKafkaAlternativo() error {
 		"--timeout-ms", "5000")
 
 	if err := consumeCmd.Run(); err != nil {
-		fmt.Printf("⚠️  Erro ao consumir mensagens: %v\n", err)
+		s.log.WarnContext(ctx, "erro ao consumir mensagens", "erro", err)
 	}
 
-	fmt.Printf("✅ Limpeza alternativa concluída\n")
+	s.log.InfoContext(ctx, "limpeza alternativa concluída")
 	return nil
 }
 
+// estadoParticao acumula o que foi consumido de uma partição do lote
+type estadoParticao struct {
+	header    *models.KafkaHeader
+	footer    *models.KafkaFooter
+	traceID   string
+	registros []models.Concurso
+
+	// Anomalias que não impedem a leitura mas invalidam o lote
+	malformados   int            // corpo que não pôde ser desserializado
+	antesDoHeader int            // registros que chegaram antes do header
+	outroLote     map[string]int // registros com header "lote" de outro lote, por lote
+}
+
+// tipoMensagemLegado identifica mensagens sem headers pelo conteúdo do corpo
+func tipoMensagemLegado(valor []byte) string {
+	var campos map[string]json.RawMessage
+	if err := json.Unmarshal(valor, &campos); err != nil {
+		return ""
+	}
+	if _, ok := campos["total_esperado"]; ok {
+		return kafka.TipoHeader
+	}
+	if _, ok := campos["total_processado"]; ok {
+		return kafka.TipoFooter
+	}
+	return kafka.TipoRegistro
+}
+
+// gerarTraceID gera um identificador aleatório para correlacionar as mensagens do lote
+func gerarTraceID() string {
+	b := make([]byte, 16)
+	if _, err := cryptorand.Read(b); err != nil {
+		return fmt.Sprintf("%032x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// Motivos de rejeição de lote (label "motivo" de concurso_registros_rejeitados_total)
+const (
+	rejeicaoHeaderAusente      = "header_ausente"
+	rejeicaoFooterAusente      = "footer_ausente"
+	rejeicaoContagemDivergente = "contagem_divergente"
+	rejeicaoLoteMisto          = "lote_misto"
+	rejeicaoStatusInvalido     = "status_invalido"
+)
+
+// registrarFalhaLote salva o lote rejeitado para análise e envia cada registro
+// para o tópico de erros. Roda até o fim mesmo com ctx cancelado.
+func (s *ConcursoService) registrarFalhaLote(ctx context.Context, data string, lote string, loteArquivo string, traceID string, status string, rejeicao string, motivo string, registros []models.Concurso) {
+	ctx = context.WithoutCancel(ctx)
+	metricas.RegistrosRejeitados.WithLabelValues(rejeicao).Add(float64(len(registros)))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("rejeicao", rejeicao), attribute.Int("registros_rejeitados", len(registros)))
+
+	// Salvar registros para análise
+	if err := s.gerarLogLoteErro(ctx, data, loteArquivo, motivo, registros); err != nil {
+		s.log.WarnContext(ctx, "erro ao gerar log de erro", "erro", err)
+	}
+
+	// Rejeitados e execução no banco, para a reconciliação
+	if err := s.repo.RegistrarRejeitados(ctx, data, lote, motivo, registros); err != nil {
+		s.log.WarnContext(ctx, "erro ao registrar rejeitados", "erro", err)
+	}
+	s.registrarExecucao(ctx, models.ExecucaoPipeline{Tipo: models.ExecucaoConsumo, Data: data, Lote: lote, TraceID: traceID, Total: len(registros), Status: status, Motivo: motivo})
+
+	// Enviar erro para tópico Kafka e coletar IDs
+	produtor, err := kafka.NovoProdutor()
+	if err != nil {
+		s.log.WarnContext(ctx, "erro ao inicializar produtor para o tópico de erros", "erro", err)
+	} else {
+		defer produtor.Close()
+	}
+	var idsLinhaKafka []string
+	for i, registro := range registros {
+		idLinhaKafka := fmt.Sprintf("%s_%d", lote, i)
+		idsLinhaKafka = append(idsLinhaKafka, idLinhaKafka)
+		if produtor == nil {
+			continue
+		}
+		if err := s.enviarErroParaKafka(ctx, produtor, data, lote, idLinhaKafka, models.NovaConcursoMensagem(registro), motivo); err != nil {
+			s.log.WarnContext(ctx, "erro ao enviar registro rejeitado para o Kafka", "erro", err)
+		}
+	}
+
+	// Salvar IDs das linhas Kafka
+	if err := s.salvarIDsLinhaKafka(ctx, data, loteArquivo, idsLinhaKafka, motivo); err != nil {
+		s.log.WarnContext(ctx, "erro ao salvar IDs", "erro", err)
+	}
+}
+
+// evento registra a linha de log e publica o mesmo evento no job em andamento
+func (s *ConcursoService) evento(ctx context.Context, tipo string, mensagem string, args ...any) {
+	s.log.InfoContext(ctx, mensagem, args...)
+	jobs.Publicar(ctx, tipo, mensagem, args...)
+}
+
+// registrarExecucao grava a execução em pipeline_execucao; falhar aqui não
+// interrompe o pipeline, e a gravação acontece mesmo com ctx cancelado
+func (s *ConcursoService) registrarExecucao(ctx context.Context, execucao models.ExecucaoPipeline) {
+	execucao.Principal = principalDe(ctx)
+	if execucao.Status == "sucesso" {
+		metricas.UltimoSucesso.WithLabelValues(execucao.Tipo, execucao.Data).SetToCurrentTime()
+	}
+	if err := s.repo.RegistrarExecucao(context.WithoutCancel(ctx), execucao); err != nil {
+		s.log.WarnContext(ctx, "erro ao registrar execução", "erro", err)
+	}
+}
+
+// principalDe retorna quem disparou a execução pela API; o contexto dos jobs
+// herda o da requisição. Vazio fora de uma chamada da API.
+func principalDe(ctx context.Context) string {
+	principal, _ := autenticacao.PrincipalDe(ctx)
+	return principal.Nome
+}
+
+// campoChaveMensagem retorna o campo usado como chave das mensagens (KAFKA_MESSAGE_KEY)
+func campoChaveMensagem() string {
+	campo := os.Getenv("KAFKA_MESSAGE_KEY")
+	if campo == "" {
+		campo = "concurso.id"
+	}
+	return campo
+}
+
+// chaveMensagem monta a chave do registro a partir do campo configurado.
+// Campo desconhecido (ex: "nenhuma") envia sem chave.
+func chaveMensagem(campo string, registro models.Concurso) string {
+	switch campo {
+	case "concurso.id":
+		return strconv.Itoa(registro.ID)
+	case "concurso.nome":
+		return registro.Nome
+	case "concurso.status":
+		return registro.Status.String
+	case "concurso.data_prova":
+		return registro.DataProva.Format("2006-01-02")
+	default:
+		return ""
+	}
+}
+
 // formatarTempo formata duração para string legível
 func (s *ConcursoService) formatarTempo(d time.Duration) string {
 	if d < time.Second {
@@ -698,56 +910,62 @@ func (s *ConcursoService) extrairDataDoLote(lote string) string {
 }
 
 // gerarLogExtracao gera log de extração
-func (s *ConcursoService) gerarLogExtracao(data string, lote string, total int, tempoTotal time.Duration) error {
+func (s *ConcursoService) gerarLogExtracao(ctx context.Context, data string, lote string, traceID string, total int, tempoTotal time.Duration, falhas InjecaoFalhas, status string) error {
 	// Criar diretório se não existir
 	logDir := filepath.Join("logs", data)
 	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return fmt.Errorf("erro ao criar diretório de log: %v", err)
+		return fmt.Errorf("erro ao criar diretório de log: %w", err)
 	}
 
 	logData := models.ExtracaoLog{
-		Data:          data,
-		Lote:          lote,
-		TotalExtraido: total,
-		TempoExecucao: s.formatarTempo(tempoTotal),
-		Timestamp:     time.Now(),
+		Data:            data,
+		Lote:            lote,
+		TraceID:         traceID,
+		Principal:       principalDe(ctx),
+		TotalExtraido:   total,
+		TempoExecucao:   s.formatarTempo(tempoTotal),
+		Status:          status,
+		FalhasInjetadas: falhas.Lista(),
+		Timestamp:       time.Now(),
 	}
 
 	jsonData, err := json.MarshalIndent(logData, "", "  ")
 	if err != nil {
-		return fmt.Errorf("erro ao serializar log: %v", err)
+		return fmt.Errorf("erro ao serializar log: %w", err)
 	}
 
 	filename := filepath.Join(logDir, fmt.Sprintf("extracao_%s.json", lote))
 	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
-		return fmt.Errorf("erro ao salvar log: %v", err)
+		return fmt.Errorf("erro ao salvar log: %w", err)
 	}
 
-	fmt.Printf("📄 Log de extração salvo em: %s\n", filename)
+	s.log.InfoContext(ctx, "log de extração salvo", "arquivo", filename)
 	return nil
 }
 
 // gerarLogKafkaCarga gera log de carga no Kafka
-func (s *ConcursoService) gerarLogKafkaCarga(header models.KafkaHeader, footer models.KafkaFooter, tempoTotal time.Duration) error {
+func (s *ConcursoService) gerarLogKafkaCarga(ctx context.Context, header models.KafkaHeader, footer models.KafkaFooter, enviadosPorParticao map[int32]int, tempoTotal time.Duration) error {
 	// Usar a data atual já que o lote agora tem timestamp
 	data := time.Now().Format("2006-01-02")
 
 	// Criar diretório se não existir
 	logDir := filepath.Join("logs", data)
 	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return fmt.Errorf("erro ao criar diretório de log: %v", err)
+		return fmt.Errorf("erro ao criar diretório de log: %w", err)
 	}
 
 	logData := models.KafkaCargaLog{
-		Header:     header,
-		Footer:     footer,
-		TempoEnvio: s.formatarTempo(tempoTotal),
-		Timestamp:  time.Now(),
+		Header:              header,
+		Footer:              footer,
+		Principal:           principalDe(ctx),
+		EnviadosPorParticao: enviadosPorParticao,
+		TempoEnvio:          s.formatarTempo(tempoTotal),
+		Timestamp:           time.Now(),
 	}
 
 	jsonData, err := json.MarshalIndent(logData, "", "  ")
 	if err != nil {
-		return fmt.Errorf("erro ao serializar log: %v", err)
+		return fmt.Errorf("erro ao serializar log: %w", err)
 	}
 
 	// Gerar nome de arquivo válido baseado no timestamp atual
@@ -755,50 +973,53 @@ func (s *ConcursoService) gerarLogKafkaCarga(header models.KafkaHeader, footer m
 	loteArquivo := fmt.Sprintf("concurso%s", agora.Format("02012006_150405"))
 	filename := filepath.Join(logDir, fmt.Sprintf("kafka_carga_%s.json", loteArquivo))
 	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
-		return fmt.Errorf("erro ao salvar log: %v", err)
+		return fmt.Errorf("erro ao salvar log: %w", err)
 	}
 
-	fmt.Printf("📄 Log de carga Kafka salvo em: %s\n", filename)
+	s.log.InfoContext(ctx, "log de carga Kafka salvo", "arquivo", filename)
 	return nil
 }
 
 // gerarLogConsumo gera log de consumo
-func (s *ConcursoService) gerarLogConsumo(data string, lote string, totalConsumido int, tempoTotal time.Duration, status string) error {
+func (s *ConcursoService) gerarLogConsumo(ctx context.Context, data string, lote string, traceID string, totalConsumido int, tempoTotal time.Duration, status string, insercao *models.InsercaoLog) error {
 	// Criar diretório se não existir
 	logDir := filepath.Join("logs", data)
 	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return fmt.Errorf("erro ao criar diretório de log: %v", err)
+		return fmt.Errorf("erro ao criar diretório de log: %w", err)
 	}
 
 	logData := models.ConsumoLog{
 		Data:               data,
 		Lote:               lote,
+		TraceID:            traceID,
+		Principal:          principalDe(ctx),
 		TotalConsumido:     totalConsumido,
 		TempoProcessamento: s.formatarTempo(tempoTotal),
 		Status:             status,
+		Insercao:           insercao,
 		Timestamp:          time.Now(),
 	}
 
 	jsonData, err := json.MarshalIndent(logData, "", "  ")
 	if err != nil {
-		return fmt.Errorf("erro ao serializar log: %v", err)
+		return fmt.Errorf("erro ao serializar log: %w", err)
 	}
 
 	filename := filepath.Join(logDir, fmt.Sprintf("consumo_%s.json", lote))
 	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
-		return fmt.Errorf("erro ao salvar log: %v", err)
+		return fmt.Errorf("erro ao salvar log: %w", err)
 	}
 
-	fmt.Printf("📄 Log de consumo salvo em: %s\n", filename)
+	s.log.InfoContext(ctx, "log de consumo salvo", "arquivo", filename)
 	return nil
 }
 
 // gerarLogLoteErro gera log de erro de lote
-func (s *ConcursoService) gerarLogLoteErro(data string, lote string, motivo string, registrosComErro []models.Concurso) error {
+func (s *ConcursoService) gerarLogLoteErro(ctx context.Context, data string, lote string, motivo string, registrosComErro []models.Concurso) error {
 	// Criar diretório se não existir
 	logDir := filepath.Join("logs", data)
 	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return fmt.Errorf("erro ao criar diretório de log: %v", err)
+		return fmt.Errorf("erro ao criar diretório de log: %w", err)
 	}
 
 	// Calcular estatísticas
@@ -818,34 +1039,41 @@ func (s *ConcursoService) gerarLogLoteErro(data string, lote string, motivo stri
 		Data:               data,
 		Lote:               lote,
 		Motivo:             motivo,
+		Principal:          principalDe(ctx),
 		TotalRegistros:     totalRegistros,
 		RegistrosValidos:   registrosValidos,
 		RegistrosInvalidos: registrosInvalidos,
-		RegistrosComErro:   registrosComErro,
+		RegistrosComErro:   models.NovasConcursoMensagens(registrosComErro),
 		Timestamp:          time.Now(),
 	}
 
 	jsonData, err := json.MarshalIndent(logData, "", "  ")
 	if err != nil {
-		return fmt.Errorf("erro ao serializar log: %v", err)
+		return fmt.Errorf("erro ao serializar log: %w", err)
 	}
 
 	filename := filepath.Join(logDir, fmt.Sprintf("lote_erro_%s.json", lote))
 	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
-		return fmt.Errorf("erro ao salvar log: %v", err)
+		return fmt.Errorf("erro ao salvar log: %w", err)
 	}
 
-	fmt.Printf("📄 Log de erro salvo em: %s\n", filename)
-	fmt.Printf("📊 Estatísticas: %d total, %d válidos, %d inválidos\n", totalRegistros, registrosValidos, registrosInvalidos)
+	s.log.InfoContext(ctx, "log de erro salvo", "arquivo", filename, "total", totalRegistros, "validos", registrosValidos, "invalidos", registrosInvalidos)
 	return nil
 }
 
-// gerarLogErroDetalhado gera log de erro com stack trace e payload
-func (s *ConcursoService) gerarLogErroDetalhado(data string, categoria string, mensagem string, err error, payload interface{}) error {
+// gerarLogErroDetalhado gera log de erro com stack trace e payload. A
+// categoria (BANCO, KAFKA, LOTE) vem do tipo do erro (ver CategoriaErro).
+// Cancelamentos não são erros: ficam só no log de extração/consumo.
+func (s *ConcursoService) gerarLogErroDetalhado(ctx context.Context, data string, mensagem string, err error, payload interface{}) error {
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	categoria := CategoriaErro(err)
+
 	// Criar diretório se não existir
 	logDir := filepath.Join("logs", data)
 	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return fmt.Errorf("erro ao criar diretório de log: %v", err)
+		return fmt.Errorf("erro ao criar diretório de log: %w", err)
 	}
 
 	// Capturar stack trace completo
@@ -863,6 +1091,7 @@ func (s *ConcursoService) gerarLogErroDetalhado(data string, categoria string, m
 	logData := models.ErroLog{
 		Categoria:  categoria,
 		Mensagem:   mensagem,
+		Principal:  principalDe(ctx),
 		ErrorTrace: stackTrace,
 		LinhaErro:  linhaErro,
 		Payload:    payload,
@@ -871,28 +1100,21 @@ func (s *ConcursoService) gerarLogErroDetalhado(data string, categoria string, m
 
 	jsonData, err := json.MarshalIndent(logData, "", "  ")
 	if err != nil {
-		return fmt.Errorf("erro ao serializar log: %v", err)
+		return fmt.Errorf("erro ao serializar log: %w", err)
 	}
 
 	filename := filepath.Join(logDir, fmt.Sprintf("erros_%s_%s.json", categoria, time.Now().Format("20060102_150405")))
 	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
-		return fmt.Errorf("erro ao salvar log: %v", err)
+		return fmt.Errorf("erro ao salvar log: %w", err)
 	}
 
-	fmt.Printf("📄 Log de erro detalhado salvo em: %s\n", filename)
+	s.log.InfoContext(ctx, "log de erro detalhado salvo", "arquivo", filename)
 	return nil
 }
 
 // enviarErroParaKafka envia erro para tópico de erros do Kafka
-func (s *ConcursoService) enviarErroParaKafka(data string, idLinhaKafka string, payload interface{}, motivo string) error {
-	// Inicializar produtor se necessário
-	if kafka.Producer == nil {
-		if err := kafka.InitProducer(); err != nil {
-			return fmt.Errorf("erro ao inicializar produtor para erro: %v", err)
-		}
-	}
-
-	erroKafka := models.ErroKafkaLog{
+func (s *ConcursoService) enviarErroParaKafka(ctx context.Context, produtor *kafka.Produtor, data string, lote string, idLinhaKafka string, payload interface{}, motivo string) error {
+	registroErro := models.ErroKafkaLog{
 		IDLinhaKafka: idLinhaKafka,
 		Payload:      payload,
 		Motivo:       motivo,
@@ -901,20 +1123,21 @@ func (s *ConcursoService) enviarErroParaKafka(data string, idLinhaKafka string,
 
 	// Enviar para tópico de erros
 	topicErros := "concurso_erros"
-	if err := kafka.SendMessage(topicErros, erroKafka); err != nil {
-		return fmt.Errorf("erro ao enviar erro para Kafka: %v", err)
+	meta := kafka.Metadados{Lote: lote, Tipo: kafka.TipoErro, Data: data}
+	if _, err := produtor.SendMessage(ctx, topicErros, idLinhaKafka, meta, registroErro); err != nil {
+		return erroKafka("enviar_erro", "erro ao enviar erro para Kafka", err)
 	}
 
-	fmt.Printf("📤 Erro enviado para tópico Kafka: %s (ID: %s)\n", topicErros, idLinhaKafka)
+	s.log.DebugContext(ctx, "registro rejeitado enviado ao tópico de erros", "topico", topicErros, "id_linha", idLinhaKafka)
 	return nil
 }
 
 // salvarIDsLinhaKafka salva IDs das linhas Kafka em arquivo texto
-func (s *ConcursoService) salvarIDsLinhaKafka(data string, lote string, idsLinhaKafka []string, motivo string) error {
+func (s *ConcursoService) salvarIDsLinhaKafka(ctx context.Context, data string, lote string, idsLinhaKafka []string, motivo string) error {
 	// Criar diretório se não existir
 	logDir := filepath.Join("logs", data)
 	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return fmt.Errorf("erro ao criar diretório de log: %v", err)
+		return fmt.Errorf("erro ao criar diretório de log: %w", err)
 	}
 
 	filename := filepath.Join(logDir, fmt.Sprintf("ids_linha_kafka_%s.txt", lote))
@@ -931,9 +1154,9 @@ func (s *ConcursoService) salvarIDsLinhaKafka(data string, lote string, idsLinha
 	}
 
 	if err := os.WriteFile(filename, []byte(conteudo), 0644); err != nil {
-		return fmt.Errorf("erro ao salvar arquivo de IDs: %v", err)
+		return fmt.Errorf("erro ao salvar arquivo de IDs: %w", err)
 	}
 
-	fmt.Printf("📄 IDs das linhas Kafka salvos em: %s\n", filename)
+	s.log.InfoContext(ctx, "IDs das linhas Kafka salvos", "arquivo", filename)
 	return nil
 }
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"concurso-go-app/internal/models"
+)
+
+// ListarConcursos consulta a tabela de origem. Filtro e campos já devem ter
+// sido validados pelo handler.
+func (s *ConcursoService) ListarConcursos(ctx context.Context, filtro models.FiltroConsulta, campos []string) (*models.PaginaConsulta, error) {
+	limite := filtro.Limite
+	filtro.Limite++ // Um a mais para saber se há próxima página
+	registros, err := s.repo.ConsultarConcursos(ctx, filtro)
+	if err != nil {
+		return nil, erroBanco("consultar_concursos", "erro ao consultar concursos", err)
+	}
+
+	itens := make([]models.ConcursoConsulta, 0, len(registros))
+	for _, registro := range registros {
+		itens = append(itens, models.ConcursoConsulta{ConcursoMensagem: models.NovaConcursoMensagem(registro)})
+	}
+	return montarPagina(itens, limite, filtro.Ordem, campos)
+}
+
+// ListarProcessados consulta concurso_processado
+func (s *ConcursoService) ListarProcessados(ctx context.Context, filtro models.FiltroConsulta, campos []string) (*models.PaginaConsulta, error) {
+	limite := filtro.Limite
+	filtro.Limite++
+	registros, err := s.repo.ConsultarProcessados(ctx, filtro)
+	if err != nil {
+		return nil, erroBanco("consultar_processados", "erro ao consultar concursos processados", err)
+	}
+
+	itens := make([]models.ConcursoConsulta, 0, len(registros))
+	for _, registro := range registros {
+		itens = append(itens, models.ConcursoConsulta{
+			ConcursoMensagem: models.NovaConcursoMensagem(registro.Concurso),
+			Lote:             registro.Lote.String,
+		})
+	}
+	return montarPagina(itens, limite, filtro.Ordem, campos)
+}
+
+// montarPagina corta o registro extra, gera o cursor da próxima página e
+// aplica a seleção de campos
+func montarPagina(itens []models.ConcursoConsulta, limite int, ordem string, campos []string) (*models.PaginaConsulta, error) {
+	pagina := &models.PaginaConsulta{Dados: make([]interface{}, 0, len(itens))}
+
+	if len(itens) > limite {
+		itens = itens[:limite]
+		ultimo := itens[len(itens)-1]
+		cursor := models.Cursor{ID: ultimo.ID}
+		switch ordem {
+		case "nome":
+			cursor.Valor = ultimo.Nome
+		case "data_prova":
+			cursor.Valor = ultimo.DataProva.Format(models.FormatoData)
+		}
+		pagina.ProximoCursor = cursor.Codificar()
+	}
+
+	for _, item := range itens {
+		if len(campos) == 0 {
+			pagina.Dados = append(pagina.Dados, item)
+			continue
+		}
+		selecionado, err := selecionarCampos(item, campos)
+		if err != nil {
+			return nil, err
+		}
+		pagina.Dados = append(pagina.Dados, selecionado)
+	}
+	return pagina, nil
+}
+
+// selecionarCampos mantém só os campos pedidos, com a mesma serialização do item completo
+func selecionarCampos(item models.ConcursoConsulta, campos []string) (map[string]json.RawMessage, error) {
+	dados, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	var completo map[string]json.RawMessage
+	if err := json.Unmarshal(dados, &completo); err != nil {
+		return nil, err
+	}
+
+	selecionado := make(map[string]json.RawMessage, len(campos))
+	for _, campo := range campos {
+		if valor, ok := completo[campo]; ok {
+			selecionado[campo] = valor
+		} else {
+			selecionado[campo] = json.RawMessage("null") // lote vazio é omitido no item completo
+		}
+	}
+	return selecionado, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Erros do pipeline. Use errors.Is para classificar: ErroOperacao casa com
+// ErrBanco/ErrKafka e ErroLote com ErrHeaderAusente, ErrFooterAusente,
+// ErrLoteMisto ou ErrContagemDivergente.
+var (
+	// ErrSemRegistros indica que não há registros de origem para a data
+	ErrSemRegistros = errors.New("nenhum registro encontrado")
+	// ErrHeaderAusente indica lote consumido sem header (em alguma partição)
+	ErrHeaderAusente = errors.New("header não encontrado")
+	// ErrFooterAusente indica lote consumido sem footer (em alguma partição)
+	ErrFooterAusente = errors.New("footer não encontrado")
+	// ErrLoteMisto indica registros de outro lote no tópico do lote
+	ErrLoteMisto = errors.New("registros de outro lote")
+	// ErrContagemDivergente indica totais de header, footer e registros que não batem
+	ErrContagemDivergente = errors.New("lote inconsistente")
+	// ErrBanco é a categoria das falhas no banco de dados
+	ErrBanco = errors.New("erro no banco de dados")
+	// ErrBancoIndisponivel indica que o banco não respondeu ao ping
+	ErrBancoIndisponivel = errors.New("banco de dados indisponível")
+	// ErrKafka é a categoria das falhas ao produzir ou consumir no Kafka
+	ErrKafka = errors.New("erro no Kafka")
+)
+
+// Categorias gravadas no log de erro detalhado (erros_<categoria>_*.json)
+const (
+	CategoriaBanco        = "BANCO"
+	CategoriaKafka        = "KAFKA"
+	CategoriaLote         = "LOTE"
+	CategoriaDesconhecida = "DESCONHECIDO"
+)
+
+// ErroOperacao é a falha de uma operação de infraestrutura: Categoria é
+// ErrBanco ou ErrKafka e Causa o erro original
+type ErroOperacao struct {
+	Categoria error
+	Operacao  string
+	Mensagem  string
+	Causa     error
+}
+
+func (e *ErroOperacao) Error() string {
+	return fmt.Sprintf("%s: %v", e.Mensagem, e.Causa)
+}
+
+func (e *ErroOperacao) Unwrap() []error {
+	return []error{e.Categoria, e.Causa}
+}
+
+func erroBanco(operacao string, mensagem string, causa error) error {
+	return &ErroOperacao{Categoria: ErrBanco, Operacao: operacao, Mensagem: mensagem, Causa: causa}
+}
+
+func erroKafka(operacao string, mensagem string, causa error) error {
+	return &ErroOperacao{Categoria: ErrKafka, Operacao: operacao, Mensagem: mensagem, Causa: causa}
+}
+
+// ErroLote é a rejeição de um lote na validação: Tipo é ErrHeaderAusente,
+// ErrFooterAusente, ErrLoteMisto ou ErrContagemDivergente
+type ErroLote struct {
+	Tipo   error
+	Data   string
+	Lote   string
+	Motivo string
+}
+
+func (e *ErroLote) Error() string {
+	if e.Motivo == "" {
+		return e.Tipo.Error()
+	}
+	return fmt.Sprintf("%v: %s", e.Tipo, e.Motivo)
+}
+
+func (e *ErroLote) Unwrap() error {
+	return e.Tipo
+}
+
+// CategoriaErro classifica o erro para logs e métricas
+func CategoriaErro(err error) string {
+	var erroLote *ErroLote
+	switch {
+	case errors.Is(err, ErrBanco), errors.Is(err, ErrBancoIndisponivel):
+		return CategoriaBanco
+	case errors.Is(err, ErrKafka):
+		return CategoriaKafka
+	case errors.As(err, &erroLote):
+		return CategoriaLote
+	default:
+		return CategoriaDesconhecida
+	}
+}
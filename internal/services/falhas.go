@@ -0,0 +1,90 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Falha é um defeito proposital no lote publicado, para exercitar os caminhos
+// de erro do consumidor
+type Falha string
+
+const (
+	FalhaSemHeader      Falha = "sem_header"      // nenhum header é enviado
+	FalhaSemFooter      Falha = "sem_footer"      // nenhum footer é enviado
+	FalhaContagemErrada Falha = "contagem_errada" // footers com total a mais
+	FalhaDuplicar       Falha = "duplicar"        // registros reenviados, como num retry do produtor
+	FalhaReordenar      Falha = "reordenar"       // um registro antes do header e outro depois do footer
+	FalhaJSONMalformado Falha = "json_malformado" // registros com corpo truncado
+	FalhaLoteMisto      Falha = "lote_misto"      // registros marcados com outro lote
+)
+
+var falhasConhecidas = []Falha{
+	FalhaSemHeader, FalhaSemFooter, FalhaContagemErrada, FalhaDuplicar,
+	FalhaReordenar, FalhaJSONMalformado, FalhaLoteMisto,
+}
+
+// InjecaoFalhas é o conjunto de falhas a aplicar numa extração
+type InjecaoFalhas map[Falha]bool
+
+// InjecaoFalhasHabilitada indica se FAULT_INJECTION_ENABLED permite publicar
+// lotes com falhas (desligado por padrão, é só para QA)
+func InjecaoFalhasHabilitada() bool {
+	habilitada, _ := strconv.ParseBool(os.Getenv("FAULT_INJECTION_ENABLED"))
+	return habilitada
+}
+
+// ParseFalhas lê uma lista separada por vírgula, ex.: "sem_footer,duplicar"
+func ParseFalhas(valor string) (InjecaoFalhas, error) {
+	falhas := make(InjecaoFalhas)
+	for _, nome := range strings.Split(valor, ",") {
+		nome = strings.TrimSpace(nome)
+		if nome == "" {
+			continue
+		}
+		falha := Falha(nome)
+		conhecida := false
+		for _, f := range falhasConhecidas {
+			if f == falha {
+				conhecida = true
+				break
+			}
+		}
+		if !conhecida {
+			return nil, fmt.Errorf("falha desconhecida: %q", nome)
+		}
+		falhas[falha] = true
+	}
+	return falhas, nil
+}
+
+// Ativa indica se a falha foi pedida (seguro com InjecaoFalhas nil)
+func (f InjecaoFalhas) Ativa(falha Falha) bool {
+	return f[falha]
+}
+
+// Lista retorna os nomes das falhas, em ordem, para os logs
+func (f InjecaoFalhas) Lista() []string {
+	var nomes []string
+	for falha := range f {
+		nomes = append(nomes, string(falha))
+	}
+	sort.Strings(nomes)
+	return nomes
+}
+
+// afetados é quantos registros recebem uma falha por registro: 1% do lote,
+// no mínimo um
+func afetados(total int) int {
+	n := total / 100
+	if n < 1 {
+		n = 1
+	}
+	if n > total {
+		n = total
+	}
+	return n
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"concurso-go-app/internal/database"
+	"concurso-go-app/internal/jobs"
+	"concurso-go-app/internal/logger"
+	"concurso-go-app/internal/metricas"
+	"concurso-go-app/internal/models"
+	"concurso-go-app/internal/rastreamento"
+	"concurso-go-app/internal/repository"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// statusInvalido é gravado quando a configuração pede registros com status
+// fora de aprovado/reprovado (rejeitados pelo consumidor)
+const statusInvalido = "pendente"
+
+// PopularDados gera a massa de teste conforme a configuração. Cada dia é
+// gerado por um worker (DB_INSERT_WORKERS, limitado pelo pool) e gravado na
+// staging, pela carga em massa com DB_BULK_LOAD=true; no fim, a limpeza do
+// modo substituir e a cópia para concurso rodam numa única transação. A
+// semente torna a massa reproduzível: o mesmo corpo com a mesma semente gera
+// os mesmos registros. Um erro ou o cancelamento de ctx deixa concurso como
+// estava.
+func (s *ConcursoService) PopularDados(ctx context.Context, config models.ConfigGeracao) (resultado *models.ResultadoGeracao, err error) {
+	if err := config.Validar(); err != nil {
+		return nil, err
+	}
+	inicio := time.Now()
+	ctx, span := rastreamento.Span(ctx, "geracao", attribute.String("modo", string(config.Modo)), attribute.String("data_inicio", config.DataInicio), attribute.String("data_fim", config.DataFim))
+	defer func() { rastreamento.Finalizar(span, err) }()
+
+	semente := time.Now().UnixNano()
+	if config.Semente != nil {
+		semente = *config.Semente
+	}
+
+	dataInicio, _ := time.Parse(models.FormatoData, config.DataInicio)
+	dataFim, _ := time.Parse(models.FormatoData, config.DataFim)
+	var dias []time.Time
+	for data := dataInicio; !data.After(dataFim); data = data.AddDate(0, 0, 1) {
+		dias = append(dias, data)
+	}
+
+	workers, err := strconv.Atoi(os.Getenv("DB_INSERT_WORKERS"))
+	if err != nil || workers < 1 {
+		workers = 4
+	}
+	workers = database.LimitarWorkers(database.DB, workers)
+
+	usarCarga, _ := strconv.ParseBool(os.Getenv("DB_BULK_LOAD"))
+	geracao := s.repo.IniciarGeracao(usarCarga)
+	defer geracao.Descartar(ctx)
+
+	s.evento(ctx, jobs.EventoFase, "iniciando população de dados", "fase", "geracao", "modo", config.Modo, "semente", semente, "workers", workers, "data_inicio", config.DataInicio, "data_fim", config.DataFim)
+	progresso := logger.NovoProgresso(s.log, "dias gerados")
+	diasConcluidos := 0
+
+	resultado = &models.ResultadoGeracao{
+		Modo:      config.Modo,
+		Semente:   semente,
+		PorData:   make(map[string]int),
+		PorStatus: make(map[string]int),
+	}
+	var mu sync.Mutex
+
+	indices := make(chan int)
+	falhou := make(chan struct{})
+	var primeiroErro error
+	var once sync.Once
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				// Um gerador por dia, derivado da semente: o resultado não
+				// depende de qual worker pegou o dia
+				rng := rand.New(rand.NewSource(semente + int64(i)))
+				porStatus, err := s.popularDia(ctx, geracao, dias[i], config, rng)
+				if err != nil {
+					once.Do(func() {
+						primeiroErro = err
+						close(falhou)
+					})
+					return
+				}
+
+				mu.Lock()
+				total := 0
+				for status, n := range porStatus {
+					resultado.PorStatus[status] += n
+					total += n
+				}
+				resultado.PorData[dias[i].Format(models.FormatoData)] = total
+				resultado.TotalInserido += total
+				diasConcluidos++
+				concluidos := diasConcluidos
+				mu.Unlock()
+				s.log.DebugContext(logger.ComAtributos(ctx, slog.String(logger.ChaveData, dias[i].Format(models.FormatoData))), "dia gerado", "registros", total)
+				progresso.Registrar(ctx, concluidos, len(dias))
+			}
+		}()
+	}
+
+distribuir:
+	for i := range dias {
+		select {
+		case indices <- i:
+		case <-falhou:
+			break distribuir
+		case <-ctx.Done():
+			break distribuir
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	resultado.TempoExecucao = s.formatarTempo(time.Since(inicio))
+	if primeiroErro != nil {
+		return resultado, primeiroErro
+	}
+	if err := ctx.Err(); err != nil {
+		s.log.WarnContext(ctx, "geração cancelada, nada publicado em concurso", "gerados", resultado.TotalInserido)
+		return resultado, err
+	}
+
+	s.evento(ctx, jobs.EventoFase, "publicando registros em concurso", "fase", "publicacao", "registros", resultado.TotalInserido, "metodo", geracao.Metodo())
+	if err := geracao.Publicar(ctx, config.Modo == models.ModoSubstituir); err != nil {
+		return resultado, erroBanco("publicar_concursos", "erro ao publicar registros em concurso", err)
+	}
+	resultado.TempoExecucao = s.formatarTempo(time.Since(inicio))
+
+	metricas.ObservarFase("geracao", "total", inicio)
+	s.evento(ctx, jobs.EventoFase, "população de dados concluída", "fase", "concluida", "inseridos", resultado.TotalInserido, "duracao", resultado.TempoExecucao)
+	return resultado, nil
+}
+
+// popularDia gera os registros de uma data, grava-os na staging da geração e
+// devolve a contagem por status ("null" para status NULL)
+func (s *ConcursoService) popularDia(ctx context.Context, geracao repository.Geracao, data time.Time, config models.ConfigGeracao, rng *rand.Rand) (porStatus map[string]int, err error) {
+	dataTexto := data.Format(models.FormatoData)
+	ctx, span := rastreamento.Span(ctx, "geracao.dia", attribute.String("data", dataTexto))
+	defer func() { rastreamento.Finalizar(span, err) }()
+	registros, taxaAprovado, taxaNulo, taxaInvalido := config.ParaDia(dataTexto)
+
+	// NULL e inválidos em quantidade exata, posições sorteadas
+	nulos := int(math.Round(float64(registros) * taxaNulo))
+	invalidos := int(math.Round(float64(registros) * taxaInvalido))
+	if nulos+invalidos > registros {
+		invalidos = registros - nulos
+	}
+	status := make([]sql.NullString, registros)
+	for i := range status {
+		switch {
+		case i < nulos:
+			// status.Valid = false (NULL)
+		case i < nulos+invalidos:
+			status[i] = sql.NullString{String: statusInvalido, Valid: true}
+		case rng.Float64() < taxaAprovado:
+			status[i] = sql.NullString{String: "aprovado", Valid: true}
+		default:
+			status[i] = sql.NullString{String: "reprovado", Valid: true}
+		}
+	}
+	rng.Shuffle(len(status), func(i, j int) { status[i], status[j] = status[j], status[i] })
+
+	const batchSize = 10000
+	porStatus = make(map[string]int)
+	batch := make([]models.Concurso, 0, batchSize)
+	for i := 1; i <= registros; i++ {
+		st := status[i-1]
+		nome := fmt.Sprintf("Candidato_%d_%s", i, dataTexto)
+		// ID leva a ordem no dia, mantida ao publicar em concurso
+		batch = append(batch, models.Concurso{ID: i, Nome: nome, Status: st, DataProva: data})
+
+		if st.Valid {
+			porStatus[st.String]++
+		} else {
+			porStatus["null"]++
+		}
+
+		if i%batchSize == 0 || i == registros {
+			if err := geracao.Inserir(ctx, batch); err != nil {
+				return nil, erroBanco("inserir_concursos", "erro ao inserir batch de "+dataTexto, err)
+			}
+			batch = batch[:0]
+		}
+	}
+
+	return porStatus, nil
+}
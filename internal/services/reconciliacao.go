@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+
+	"concurso-go-app/internal/models"
+)
+
+// limiteIDsReconciliacao é quantos ids faltando/extras são listados por data
+const limiteIDsReconciliacao = 100
+
+// Reconciliar compara, para cada data entre de e ate, a origem (concurso), o
+// que foi extraído e consumido (pipeline_execucao) e o destino
+// (concurso_processado e concurso_rejeitado)
+func (s *ConcursoService) Reconciliar(ctx context.Context, de string, ate string) ([]models.ReconciliacaoData, error) {
+	datas, err := s.repo.Reconciliar(ctx, de, ate, limiteIDsReconciliacao)
+	if err != nil {
+		return nil, erroBanco("reconciliar", "erro ao reconciliar", err)
+	}
+	duplicados, err := s.repo.ContarDuplicados(ctx, de, ate)
+	if err != nil {
+		return nil, erroBanco("contar_duplicados", "erro ao contar duplicados", err)
+	}
+
+	for i := range datas {
+		d := &datas[i]
+		if d.TotalOrigem > 0 && d.UltimaExtracao == nil {
+			d.Alertas = append(d.Alertas, models.AlertaNaoExtraido)
+		}
+		// Extração cancelada não tem lote completo para consumir
+		if d.UltimaExtracao != nil && d.UltimaExtracao.Status != "cancelado" && (d.UltimoConsumo == nil || d.UltimoConsumo.Lote != d.UltimaExtracao.Lote) {
+			d.Alertas = append(d.Alertas, models.AlertaExtraidoNaoConsumido)
+		}
+		if d.UltimoConsumo != nil && (d.TotalFaltando > 0 || d.TotalConsumido < d.TotalExtraido) {
+			d.Alertas = append(d.Alertas, models.AlertaCargaParcial)
+		}
+		if d.TotalExtras > 0 {
+			d.Alertas = append(d.Alertas, models.AlertaIDsExtras)
+		}
+		if duplicados[d.Data] > 0 {
+			d.Alertas = append(d.Alertas, models.AlertaDuplicados)
+		}
+	}
+	return datas, nil
+}
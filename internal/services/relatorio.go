@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"concurso-go-app/internal/models"
+)
+
+// RelatorioResultados agrega processados (aprovado/reprovado) e rejeitados
+// por período. agrupar é dia, semana (ISO) ou mês.
+func (s *ConcursoService) RelatorioResultados(ctx context.Context, de string, ate string, agrupar string) (*models.RelatorioResultados, error) {
+	processados, err := s.repo.ContarProcessadosPorStatus(ctx, de, ate)
+	if err != nil {
+		return nil, erroBanco("contar_processados", "erro ao contar processados", err)
+	}
+	rejeitados, err := s.repo.ContarRejeitadosPorStatus(ctx, de, ate)
+	if err != nil {
+		return nil, erroBanco("contar_rejeitados", "erro ao contar rejeitados", err)
+	}
+
+	relatorio := &models.RelatorioResultados{De: de, Ate: ate, Agrupar: agrupar, Periodos: []models.LinhaRelatorio{}}
+	totais := &relatorio.Totais
+	totais.Periodo = "total"
+
+	porPeriodo := make(map[string]*models.LinhaRelatorio)
+	var ordem []string
+	linhaDo := func(data string) (*models.LinhaRelatorio, error) {
+		periodo, err := periodoDe(data, agrupar)
+		if err != nil {
+			return nil, err
+		}
+		linha, ok := porPeriodo[periodo]
+		if !ok {
+			linha = &models.LinhaRelatorio{Periodo: periodo}
+			porPeriodo[periodo] = linha
+			ordem = append(ordem, periodo)
+		}
+		return linha, nil
+	}
+
+	for _, c := range processados {
+		linha, err := linhaDo(c.Data)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range []*models.LinhaRelatorio{linha, totais} {
+			switch c.Status {
+			case "aprovado":
+				l.Aprovados += c.Total
+			case "reprovado":
+				l.Reprovados += c.Total
+			}
+			l.Total += c.Total
+		}
+	}
+	for _, c := range rejeitados {
+		linha, err := linhaDo(c.Data)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range []*models.LinhaRelatorio{linha, totais} {
+			l.Rejeitados += c.Total
+			if c.Nulo {
+				l.RejeitadosStatusNulo += c.Total
+			}
+			l.Total += c.Total
+		}
+	}
+
+	// Períodos em ordem cronológica (os formatos usados ordenam como texto)
+	sort.Strings(ordem)
+	var anterior *models.LinhaRelatorio
+	for _, periodo := range ordem {
+		linha := porPeriodo[periodo]
+		calcularTaxas(linha)
+		if anterior != nil && anterior.Aprovados+anterior.Reprovados > 0 && linha.Aprovados+linha.Reprovados > 0 {
+			variacao := linha.TaxaAprovacao - anterior.TaxaAprovacao
+			linha.VariacaoTaxaAprovacao = &variacao
+		}
+		relatorio.Periodos = append(relatorio.Periodos, *linha)
+		anterior = linha
+	}
+	calcularTaxas(totais)
+
+	return relatorio, nil
+}
+
+// EscreverRelatorioCSV gera o CSV com uma linha por período e a linha de totais
+func EscreverRelatorioCSV(w io.Writer, relatorio *models.RelatorioResultados) error {
+	escritor := csv.NewWriter(w)
+	escritor.Write([]string{"periodo", "aprovados", "reprovados", "rejeitados", "rejeitados_status_nulo", "total", "taxa_aprovacao", "taxa_nulo", "variacao_taxa_aprovacao"})
+
+	linhas := append(append([]models.LinhaRelatorio{}, relatorio.Periodos...), relatorio.Totais)
+	for _, l := range linhas {
+		variacao := ""
+		if l.VariacaoTaxaAprovacao != nil {
+			variacao = formatarTaxa(*l.VariacaoTaxaAprovacao)
+		}
+		escritor.Write([]string{
+			l.Periodo,
+			strconv.Itoa(l.Aprovados),
+			strconv.Itoa(l.Reprovados),
+			strconv.Itoa(l.Rejeitados),
+			strconv.Itoa(l.RejeitadosStatusNulo),
+			strconv.Itoa(l.Total),
+			formatarTaxa(l.TaxaAprovacao),
+			formatarTaxa(l.TaxaNulo),
+			variacao,
+		})
+	}
+	escritor.Flush()
+	return escritor.Error()
+}
+
+// periodoDe converte a data no rótulo do período: 2025-01-06, 2025-W02 ou 2025-01
+func periodoDe(data string, agrupar string) (string, error) {
+	t, err := time.Parse(models.FormatoData, data)
+	if err != nil {
+		return "", err
+	}
+	switch agrupar {
+	case models.AgruparSemana:
+		ano, semana := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", ano, semana), nil
+	case models.AgruparMes:
+		return t.Format("2006-01"), nil
+	default:
+		return data, nil
+	}
+}
+
+func calcularTaxas(l *models.LinhaRelatorio) {
+	if avaliados := l.Aprovados + l.Reprovados; avaliados > 0 {
+		l.TaxaAprovacao = float64(l.Aprovados) / float64(avaliados)
+	}
+	if l.Total > 0 {
+		l.TaxaNulo = float64(l.RejeitadosStatusNulo) / float64(l.Total)
+	}
+}
+
+func formatarTaxa(taxa float64) string {
+	return strconv.FormatFloat(taxa, 'f', 4, 64)
+}